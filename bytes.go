@@ -0,0 +1,46 @@
+package env
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var byteUnits = map[string]float64{
+	"":  1,
+	"b": 1,
+	"k": 1e3, "kb": 1e3,
+	"m": 1e6, "mb": 1e6,
+	"g": 1e9, "gb": 1e9,
+	"t": 1e12, "tb": 1e12,
+	"ki": 1 << 10, "kib": 1 << 10,
+	"mi": 1 << 20, "mib": 1 << 20,
+	"gi": 1 << 30, "gib": 1 << 30,
+	"ti": 1 << 40, "tib": 1 << 40,
+}
+
+// ParseBytes parses a size in bytes, like "512", "64KiB", "1.5G" or "10MB".
+//
+// Decimal suffixes (k, M, G, T, with an optional B) are powers of 1000,
+// binary suffixes (Ki, Mi, Gi, Ti, with an optional B) are powers of 1024. Negative sizes are rejected.
+func ParseBytes(s string) (int64, error) {
+	num := strings.TrimRightFunc(s, func(r rune) bool {
+		return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+	})
+	unit, ok := byteUnits[strings.ToLower(s[len(num):])]
+	if !ok || num == "" {
+		return 0, errors.New("invalid byte size " + strconv.Quote(s))
+	}
+	if n, err := strconv.ParseInt(num, 10, 64); err == nil && unit == 1 && n >= 0 {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || f < 0 {
+		return 0, errors.New("invalid byte size " + strconv.Quote(s))
+	}
+	f *= unit
+	if f >= 1<<63 {
+		return 0, errors.New("byte size " + strconv.Quote(s) + " is too large")
+	}
+	return int64(f), nil
+}
@@ -0,0 +1,43 @@
+package env
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	for _, c := range []struct {
+		in  string
+		exp int64
+		err bool
+	}{
+		{in: "0", exp: 0},
+		{in: "512", exp: 512},
+		{in: "512B", exp: 512},
+		{in: "64KiB", exp: 64 << 10},
+		{in: "64ki", exp: 64 << 10},
+		{in: "1.5G", exp: 1.5e9},
+		{in: "10MB", exp: 10e6},
+		{in: "1Ti", exp: 1 << 40},
+		{in: "", err: true},
+		{in: "KiB", err: true},
+		{in: "10XB", err: true},
+		{in: "-5", err: true},
+		{in: "-5KiB", err: true},
+		{in: "-0.5M", err: true},
+		{in: "9223372036854775807", exp: math.MaxInt64},
+		{in: "8EiB", err: true},
+		{in: "10000000T", err: true},
+	} {
+		n, err := ParseBytes(c.in)
+		if c.err {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %d", c.in, n)
+			}
+		} else if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+		} else if n != c.exp {
+			t.Errorf("%q: got %d, expected %d", c.in, n, c.exp)
+		}
+	}
+}
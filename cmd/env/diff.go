@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/dennwc/env"
+)
+
+// exitBreaking is the exit code of the diff command when breaking changes are found.
+const exitBreaking = 3
+
+// runDiff compares two specs and reports their differences. It exits with code 3 if any of the changes are breaking,
+// so it can be used as a release gate.
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: env diff [flags] <old> <new>\n\n"+
+			"Specs are read from .json, .yaml or .toml files, or from executables started with "+env.SpecVar+"=1.\n"+
+			"Exits with code 3 if breaking changes are found.\n\nflags:")
+		fs.PrintDefaults()
+	}
+	breaking := fs.Bool("breaking", false, "show only breaking changes")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	old, err := loadSpec(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	cur, err := loadSpec(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	changes := env.CompareSpecs(old, cur)
+	if *breaking {
+		changes = env.BreakingChanges(changes)
+	}
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if len(env.BreakingChanges(changes)) != 0 {
+		return exitError(exitBreaking)
+	}
+	return nil
+}
+
+// loadSpec reads a spec file, or runs an executable to get its spec (see env.HandleSpecFlag).
+func loadSpec(path string) (*env.Spec, error) {
+	switch filepath.Ext(path) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return env.ReadSpec(path)
+	}
+	if !filepath.IsAbs(path) && filepath.Base(path) == path {
+		path = "./" + path
+	}
+	var buf bytes.Buffer
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), env.SpecVar+"=1")
+	cmd.Stdout = &buf
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	s, err := env.ParseSpec(buf.Bytes(), "json")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
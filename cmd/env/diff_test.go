@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// run the command instead of tests, see runMain
+	if os.Getenv("ENV_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the command in a subprocess and returns its output and exit code.
+func runMain(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "ENV_TEST_MAIN=1")
+	out, err := cmd.Output()
+	var eerr *exec.ExitError
+	if errors.As(err, &eerr) {
+		return string(out), eerr.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), 0
+}
+
+func writeSpecs(t *testing.T, old, cur string) (string, string) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "old.yaml"), filepath.Join(dir, "new.yaml")
+	if err := os.WriteFile(a, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte(cur), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return a, b
+}
+
+func TestDiffExitCode(t *testing.T) {
+	old := "vars:\n  - name: PORT\n    type: int\n  - name: HOST\n    type: string\n"
+	for _, c := range []struct {
+		name string
+		cur  string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			name: "compatible",
+			cur:  old + "  - name: DEBUG\n    type: bool\n",
+			out:  "compatible: DEBUG: added\n",
+		},
+		{
+			name: "breaking",
+			cur:  "vars:\n  - name: PORT\n    type: string\n  - name: DEBUG\n    type: bool\n",
+			out:  "compatible: DEBUG: added\nbreaking: HOST: removed\ncompatible: PORT: type changed from int to string\n",
+			code: exitBreaking,
+		},
+		{
+			name: "only breaking",
+			cur:  "vars:\n  - name: PORT\n    type: string\n  - name: DEBUG\n    type: bool\n",
+			args: []string{"-breaking"},
+			out:  "breaking: HOST: removed\n",
+			code: exitBreaking,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			a, b := writeSpecs(t, old, c.cur)
+			out, code := runMain(t, append(append([]string{"diff"}, c.args...), a, b)...)
+			if out != c.out || code != c.code {
+				t.Fatalf("unexpected result (code %d):\n%s", code, out)
+			}
+		})
+	}
+}
+
+func TestDiffInvalidSpec(t *testing.T) {
+	a, b := writeSpecs(t, "vars:\n  - name: A\n", "vars:\n  - type: int\n")
+	out, code := runMain(t, "diff", a, b)
+	if code != 1 || strings.TrimSpace(out) != "" {
+		t.Fatalf("unexpected result (code %d):\n%s", code, out)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/dennwc/env"
+)
+
+// runGet prints a normalized value of the variable, see env.Normalize.
+// Invalid values are reported the same way as Go programs report them with env.Log.
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	var (
+		typ      = fs.String("type", "string", "variable type: "+strings.Join(env.Types, ", "))
+		def      = fs.String("default", "", "default value")
+		required = fs.Bool("required", false, "fail if the variable is not set")
+	)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: env get KEY [-type T] [-default V] [-required]")
+		fs.PrintDefaults()
+	}
+	// allow the key to be passed before flags
+	var key string
+	if len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+		key, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if key == "" && fs.NArg() == 1 {
+		key = fs.Arg(0)
+	} else if key == "" || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	s := *def
+	if v, ok := env.Lookup(key); ok {
+		s = v.Value
+	} else if *required {
+		return fmt.Errorf("variable %s is not set", key)
+	}
+	if s == "" {
+		fmt.Println()
+		return nil
+	}
+	out, err := env.Normalize(key, *typ, s)
+	if err != nil {
+		log.SetFlags(0)
+		env.Log(key, err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+	return nil
+}
@@ -0,0 +1,75 @@
+// Command env is a command line tool for working with environment variables declared by programs using the env package.
+//
+// Usage:
+//
+//	env <command> [flags]
+//
+// Commands:
+//
+//	diff    compare two specs and report breaking changes
+//	get     print a normalized value of a variable
+//	render  render a template with typed variables
+//	serve   serve a directory of dotenv and JSON files over HTTP
+//	setup   interactively write a .env file for variables from a spec
+//	size    check the size of the environment against exec limits
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// exitError is returned by commands to exit with a given code without printing an error.
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit code %d", int(e))
+}
+
+type command struct {
+	desc string
+	run  func(args []string) error
+}
+
+var commands = map[string]command{
+	"diff":   {"compare two specs and report breaking changes", runDiff},
+	"get":    {"print a normalized value of a variable", runGet},
+	"render": {"render a template with typed variables", runRender},
+	"serve":  {"serve a directory of dotenv and JSON files over HTTP", runServe},
+	"setup":  {"interactively write a .env file for variables from a spec", runSetup},
+	"size":   {"check the size of the environment against exec limits", runSize},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: env <command> [flags]\n\ncommands:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].desc)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		var code exitError
+		if errors.As(err, &code) {
+			os.Exit(int(code))
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dennwc/env"
+)
+
+// runRender renders a template with env.Render.
+func runRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	out := fs.String("o", "", "output file; stdout if not set")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: env render [-o FILE] [TEMPLATE]\n\nTemplate is read from stdin if not set.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	var (
+		name = "stdin"
+		data []byte
+		err  error
+	)
+	switch fs.NArg() {
+	case 0:
+		data, err = io.ReadAll(os.Stdin)
+	case 1:
+		name = filepath.Base(fs.Arg(0))
+		data, err = os.ReadFile(fs.Arg(0))
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := env.Render(&buf, name, string(data)); err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*out, buf.Bytes(), 0644)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dennwc/env/envserve"
+)
+
+// runServe serves a directory of dotenv and JSON files over HTTP, see envserve.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	var (
+		dir  = fs.String("dir", ".", "directory with dotenv and JSON files")
+		addr = fs.String("addr", "localhost:8500", "address to listen on")
+		poll = fs.Duration("poll", time.Second, "interval for checking files for changes")
+	)
+	fs.Parse(args)
+	srv, err := envserve.New(*dir)
+	if err != nil {
+		return err
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Run(context.Background(), *poll)
+	}()
+	go func() {
+		log.Printf("serving %s on http://%s", *dir, *addr)
+		errc <- http.ListenAndServe(*addr, srv)
+	}()
+	return <-errc
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/dennwc/env"
+)
+
+func runSetup(args []string) error {
+	fs := flag.NewFlagSet("setup", flag.ExitOnError)
+	var (
+		spec  = fs.String("spec", "env.yaml", "spec file with declared variables (.json, .yaml or .toml)")
+		out   = fs.String("o", ".env", "output file")
+		all   = fs.Bool("all", false, "ask for all variables, including the ones already set")
+		force = fs.Bool("f", false, "overwrite the output file if it exists")
+	)
+	fs.Parse(args)
+	s, err := env.ReadSpec(*spec)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(*out); err == nil && !*force {
+		return errors.New(*out + " already exists, use -f to overwrite")
+	}
+	w := &env.Setup{In: os.Stdin, Out: os.Stderr, All: *all}
+	if isTerminal(os.Stdin) {
+		w.ReadSecret = func() (string, error) { return readPassword(os.Stdin) }
+	}
+	var buf bytes.Buffer
+	if err := w.Run(s.Vars, &buf); err != nil {
+		return err
+	}
+	return os.WriteFile(*out, buf.Bytes(), 0600)
+}
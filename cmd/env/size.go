@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dennwc/env"
+)
+
+// runSize checks the size of the current environment against Linux exec limits.
+// It fails only if the limits are exceeded, large variables are reported as warnings.
+func runSize(args []string) error {
+	fs := flag.NewFlagSet("size", flag.ExitOnError)
+	top := fs.Int("top", 5, "number of largest variables to show")
+	fs.Parse(args)
+	r := env.CheckSize(os.Environ())
+	if r.Limit > 0 {
+		fmt.Printf("total: %d of %d bytes (%.1f%%)\n", r.Total, r.Limit, 100*float64(r.Total)/float64(r.Limit))
+	} else {
+		fmt.Printf("total: %d bytes\n", r.Total)
+	}
+	for i := 0; i < len(r.Entries) && i < *top; i++ {
+		fmt.Printf("  %8d  %s\n", r.Entries[i].Size, r.Entries[i].Key)
+	}
+	for _, p := range r.Problems() {
+		fmt.Println(p)
+	}
+	return r.Err()
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !dragonfly
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+func readPassword(f *os.File) (string, error) {
+	return "", errors.New("hidden input is not supported on this platform")
+}
@@ -0,0 +1,52 @@
+//go:build linux || darwin || freebsd || netbsd || dragonfly
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+func ioctlTermios(f *os.File, req uintptr, t *syscall.Termios) error {
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(unsafe.Pointer(t)))
+	if e != 0 {
+		return e
+	}
+	return nil
+}
+
+func isTerminal(f *os.File) bool {
+	var t syscall.Termios
+	return ioctlTermios(f, ioctlGetTermios, &t) == nil
+}
+
+// readPassword reads a line from the terminal with echo disabled.
+func readPassword(f *os.File) (string, error) {
+	var old syscall.Termios
+	if err := ioctlTermios(f, ioctlGetTermios, &old); err != nil {
+		return "", err
+	}
+	t := old
+	t.Lflag &^= syscall.ECHO
+	t.Lflag |= syscall.ICANON | syscall.ISIG
+	if err := ioctlTermios(f, ioctlSetTermios, &t); err != nil {
+		return "", err
+	}
+	defer ioctlTermios(f, ioctlSetTermios, &old)
+	// read byte by byte to not consume input past the line
+	r := bufio.NewReaderSize(oneByteReader{f}, 16)
+	line, err := r.ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+type oneByteReader struct{ f *os.File }
+
+func (r oneByteReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return r.f.Read(p)
+}
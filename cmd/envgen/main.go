@@ -0,0 +1,62 @@
+// Command envgen generates a typed configuration struct from a spec of environment variables.
+//
+// Usage:
+//
+//	//go:generate go run github.com/dennwc/env/cmd/envgen -spec config.yaml -o config_gen.go
+//
+// The spec can be written in JSON, YAML or TOML:
+//
+//	package: config
+//	struct: Config
+//	vars:
+//	  - name: HTTP_PORT
+//	    type: int
+//	    default: 8080
+//	    description: Port to listen on.
+//	    min: 1
+//	    max: 65535
+//	  - name: DB_PASSWORD
+//	    type: string
+//	    secret: true
+//	    required: true
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/dennwc/env"
+	"github.com/dennwc/env/envgen"
+)
+
+var (
+	fSpec   = flag.String("spec", "env.yaml", "spec file (.json, .yaml or .toml)")
+	fOut    = flag.String("o", "env_gen.go", "output file")
+	fPkg    = flag.String("package", "", "package name; overrides the one from spec")
+	fStruct = flag.String("type", "", "struct name; overrides the one from spec")
+)
+
+func main() {
+	flag.Parse()
+	spec, err := env.ReadSpec(*fSpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *fPkg != "" {
+		spec.Package = *fPkg
+	} else if spec.Package == "" {
+		spec.Package = os.Getenv("GOPACKAGE")
+	}
+	if *fStruct != "" {
+		spec.Struct = *fStruct
+	}
+	var buf bytes.Buffer
+	if err := envgen.Generate(&buf, spec); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*fOut, buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
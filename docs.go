@@ -0,0 +1,67 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDocs writes a Markdown table describing all variables declared or read by the program (see Vars).
+//
+// Defaults provided by sources added with UseDefaults are shown instead of the in-code defaults.
+// Defaults of secret variables are never shown.
+//
+// Variables of tagged union variants (see RegisterUnion) are listed in separate tables, one for each variant.
+func WriteDocs(w io.Writer) error {
+	var (
+		main   []Var
+		groups []string
+		byName = make(map[string][]Var)
+	)
+	for _, v := range Vars() {
+		if v.Group == "" {
+			main = append(main, v)
+			continue
+		}
+		if _, ok := byName[v.Group]; !ok {
+			groups = append(groups, v.Group)
+		}
+		byName[v.Group] = append(byName[v.Group], v)
+	}
+	if err := writeDocsTable(w, main); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if _, err := fmt.Fprintf(w, "\n#### `%s`\n\n", g); err != nil {
+			return err
+		}
+		if err := writeDocsTable(w, byName[g]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDocsTable(w io.Writer, vars []Var) error {
+	if _, err := fmt.Fprint(w, "| Name | Type | Default | Description |\n|---|---|---|---|\n"); err != nil {
+		return err
+	}
+	for _, v := range vars {
+		def := v.Default
+		if s, _, ok := lookupDefault(v.Name); ok {
+			def = s
+		}
+		if def != "" {
+			def = "`" + def + "`"
+		}
+		if v.Secret && def != "" {
+			def = "(secret)"
+		}
+		desc := strings.ReplaceAll(v.Description, "\n", " ")
+		desc = strings.ReplaceAll(desc, "|", `\|`)
+		if _, err := fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", v.Name, v.Type, def, desc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteDocs(t *testing.T) {
+	testEnv(t, nil)
+	UseDefaults(Map{"PORT": "80", "TOKEN": "dev"})
+	Declare(Var{Name: "PORT", Type: "int", Default: "8080", Description: "port to listen on"})
+	Declare(Var{Name: "TOKEN", Type: "string", Secret: true, Description: "API token"})
+	Declare(Var{Name: "NAME", Type: "string", Default: "app", Description: "first line\nsecond | line"})
+	Declare(Var{Name: "EMPTY", Type: "bool"})
+	var sb strings.Builder
+	if err := WriteDocs(&sb); err != nil {
+		t.Fatal(err)
+	}
+	exp := "| Name | Type | Default | Description |\n|---|---|---|---|\n" +
+		"| `EMPTY` | bool |  |  |\n" +
+		"| `NAME` | string | `app` | first line second \\| line |\n" +
+		"| `PORT` | int | `80` | port to listen on |\n" +
+		"| `TOKEN` | string | (secret) | API token |\n"
+	if sb.String() != exp {
+		t.Fatalf("unexpected docs:\n%s\nexpected:\n%s", sb.String(), exp)
+	}
+}
@@ -0,0 +1,242 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type dotenvValue struct {
+	value string
+	file  string
+	line  int
+}
+
+// Dotenv is a set of variables loaded from dotenv or JSON files. It implements Source.
+type Dotenv struct {
+	vals map[string]dotenvValue
+}
+
+// Lookup implements Source. The place is reported as "file:line", or as a file name for JSON files.
+func (d *Dotenv) Lookup(key string) (val, from string, ok bool) {
+	v, ok := d.vals[key]
+	if !ok {
+		return "", "", false
+	}
+	if v.line == 0 {
+		return v.value, v.file, true
+	}
+	return v.value, v.file + ":" + strconv.Itoa(v.line), true
+}
+
+// Keys implements Source.
+func (d *Dotenv) Keys() []string {
+	keys := make([]string, 0, len(d.vals))
+	for k := range d.vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// File returns the name of the file the variable was defined in.
+func (d *Dotenv) File(key string) string {
+	return d.vals[key].file
+}
+
+// ReadDotenv reads dotenv files in order. Variables from later files override the ones from earlier files.
+//
+// Each file consists of lines in the form "KEY=VALUE" (optionally prefixed by "export").
+// Values can be single-quoted (taken literally), double-quoted (with escape sequences, may span multiple lines)
+// or unquoted (trailing " #" comments are removed). Lines starting with '#' are comments.
+//
+// The "#include other.env" directive reads other file at this point, relative to the directory of the current file.
+// Variables defined after the directive override included ones.
+func ReadDotenv(files ...string) (*Dotenv, error) {
+	l := newDotenvLoader(os.ReadFile, filepath.Dir, filepath.Join, filepath.IsAbs)
+	for _, f := range files {
+		if err := l.load(f); err != nil {
+			return nil, err
+		}
+	}
+	return l.d, nil
+}
+
+// LoadDotenv reads a cascade of dotenv files from the directory and adds it as a source with Use.
+// Files are read in this order, later files override earlier ones:
+//
+//	.env
+//	.env.local          (skipped if appEnv is "test", to keep tests reproducible)
+//	.env.<appEnv>
+//	.env.<appEnv>.local
+//
+// Missing files are skipped. Process environment still takes precedence over all files.
+func LoadDotenv(dir, appEnv string) (*Dotenv, error) {
+	names := []string{".env"}
+	if appEnv != "test" {
+		names = append(names, ".env.local")
+	}
+	if appEnv != "" {
+		names = append(names, ".env."+appEnv, ".env."+appEnv+".local")
+	}
+	var files []string
+	for _, name := range names {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			files = append(files, p)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	d, err := ReadDotenv(files...)
+	if err != nil {
+		return nil, err
+	}
+	Use(d)
+	return d, nil
+}
+
+type dotenvLoader struct {
+	read  func(name string) ([]byte, error)
+	dir   func(name string) string
+	join  func(elem ...string) string
+	isAbs func(name string) bool
+	d     *Dotenv
+	stack []string
+}
+
+func newDotenvLoader(read func(string) ([]byte, error), dir func(string) string, join func(...string) string, isAbs func(string) bool) *dotenvLoader {
+	return &dotenvLoader{
+		read: read, dir: dir, join: join, isAbs: isAbs,
+		d: &Dotenv{vals: make(map[string]dotenvValue)},
+	}
+}
+
+func (l *dotenvLoader) load(file string) error {
+	for _, f := range l.stack {
+		if f == file {
+			return fmt.Errorf("dotenv include cycle: %s -> %s", strings.Join(l.stack, " -> "), file)
+		}
+	}
+	data, err := l.read(file)
+	if err != nil {
+		return err
+	}
+	l.stack = append(l.stack, file)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+	if strings.HasSuffix(file, ".json") {
+		return parseJSONVars(data, file, func(key, val string) {
+			l.d.vals[key] = dotenvValue{value: val, file: file}
+		})
+	}
+	return parseDotenv(string(data), file, func(line, _ int, key, val string) error {
+		if key == "" {
+			if !l.isAbs(val) {
+				val = l.join(l.dir(file), val)
+			}
+			return l.load(val)
+		}
+		l.d.vals[key] = dotenvValue{value: val, file: file, line: line}
+		return nil
+	})
+}
+
+// parseDotenv parses dotenv data and calls fnc for each variable with its first line number and the number of lines it spans.
+// Include directives are passed to fnc with an empty key and the file name as a value.
+func parseDotenv(data, file string, fnc func(line, n int, key, val string) error) error {
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		ln := i + 1
+		if name, ok := strings.CutPrefix(line, "#include "); ok {
+			if err := fnc(ln, 1, "", strings.TrimSpace(name)); err != nil {
+				return err
+			}
+			continue
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, val, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", file, ln)
+		}
+		val = strings.TrimSpace(val)
+		switch {
+		case strings.HasPrefix(val, "'"):
+			end := strings.IndexByte(val[1:], '\'')
+			if end < 0 {
+				return fmt.Errorf("%s:%d: unterminated single-quoted value", file, ln)
+			}
+			val = val[1 : end+1]
+		case strings.HasPrefix(val, `"`):
+			s, n, err := unquoteDotenv(lines[i:], strings.Index(lines[i], `"`))
+			if err != nil {
+				return fmt.Errorf("%s:%d: %v", file, ln, err)
+			}
+			val, i = s, i+n
+		default:
+			if j := strings.Index(val, " #"); j >= 0 {
+				val = strings.TrimSpace(val[:j])
+			}
+		}
+		if err := fnc(ln, i+2-ln, key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unquoteDotenv reads a double-quoted value starting at lines[0][start].
+// It returns the value and the number of additional lines consumed.
+func unquoteDotenv(lines []string, start int) (string, int, error) {
+	var sb strings.Builder
+	s := lines[0][start+1:]
+	for n := 0; ; {
+		for j := 0; j < len(s); j++ {
+			switch c := s[j]; c {
+			case '"':
+				return sb.String(), n, nil
+			case '\\':
+				if j+1 == len(s) {
+					sb.WriteByte(c)
+					continue
+				}
+				j++
+				switch s[j] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 'r':
+					sb.WriteByte('\r')
+				case 't':
+					sb.WriteByte('\t')
+				default:
+					sb.WriteByte(s[j])
+				}
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		n++
+		if n >= len(lines) {
+			return "", 0, errors.New("unterminated double-quoted value")
+		}
+		sb.WriteByte('\n')
+		s = lines[n]
+	}
+}
+
+// quoteDotenv formats a value for a dotenv file, quoting it if necessary.
+func quoteDotenv(val string) string {
+	if !strings.ContainsAny(val, " \t\r\n#\"'\\$`") {
+		return val
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(val) + `"`
+}
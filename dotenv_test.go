@@ -0,0 +1,161 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		in   string
+		exp  map[string]string
+	}{
+		{"plain", "A=1\n  B = 2 \nexport C=3\n", map[string]string{"A": "1", "B": "2", "C": "3"}},
+		{"comments", "# A=0\nA=1 # comment\nB=a#b\nC=\n", map[string]string{"A": "1", "B": "a#b", "C": ""}},
+		{"single quotes", `A='a "b" \n # c'`, map[string]string{"A": `a "b" \n # c`}},
+		{"double quotes", `A="a \"b\"\t\\n # c"`, map[string]string{"A": "a \"b\"\t\\n # c"}},
+		{"escapes", `A="\n\r\t\$"`, map[string]string{"A": "\n\r\t$"}},
+		{"multi-line", "A=\"line 1\nline 2\n\"\nB=2\n", map[string]string{"A": "line 1\nline 2\n", "B": "2"}},
+		{"crlf", "A=1\r\nB=\"x\r\ny\"\r\n", map[string]string{"A": "1", "B": "x\ny"}},
+		{"equals in value", "A=b=c\n", map[string]string{"A": "b=c"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := make(map[string]string)
+			err := parseDotenv(c.in, "test.env", func(_, _ int, key, val string) error {
+				got[key] = val
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.exp) {
+				t.Fatalf("got %q, expected %q", got, c.exp)
+			}
+		})
+	}
+}
+
+func TestParseDotenvLines(t *testing.T) {
+	type line struct {
+		key     string
+		line, n int
+	}
+	var got []line
+	err := parseDotenv("A=1\nB=\"x\ny\nz\"\n\n#include other.env\nC=3\n", "test.env", func(ln, n int, key, _ string) error {
+		got = append(got, line{key, ln, n})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []line{{"A", 1, 1}, {"B", 2, 3}, {"", 6, 1}, {"C", 7, 1}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	for _, c := range []struct {
+		in, err string
+	}{
+		{"A\n", "test.env:1: expected KEY=VALUE"},
+		{"=1\n", "test.env:1: expected KEY=VALUE"},
+		{"A B=1\n", "test.env:1: expected KEY=VALUE"},
+		{"A=1\nB='x\n", "test.env:2: unterminated single-quoted value"},
+		{"A=\"x\ny\n", "test.env:1: unterminated double-quoted value"},
+	} {
+		err := parseDotenv(c.in, "test.env", func(_, _ int, _, _ string) error { return nil })
+		if err == nil || err.Error() != c.err {
+			t.Errorf("%q: unexpected error: %v", c.in, err)
+		}
+	}
+}
+
+func TestReadDotenvInclude(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	abs := writeTestFile(t, dir, "abs.env", "D=abs\n")
+	writeTestFile(t, dir, "common.env", "B=common\nC=common\n")
+	writeTestFile(t, dir, "sub/base.env", "A=base\n#include ../common.env\nC=base\n#include "+abs+"\n")
+	main := writeTestFile(t, dir, "main.env", "A=main\n#include sub/base.env\nB=main\n")
+
+	d, err := ReadDotenv(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]string{"A": "base", "B": "main", "C": "base", "D": "abs"}
+	if got := dotenvValues(d); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got %q, expected %q", got, exp)
+	}
+	if _, from, _ := d.Lookup("C"); from != filepath.Join(dir, "sub", "base.env")+":3" {
+		t.Fatalf("unexpected location: %q", from)
+	}
+
+	if _, err := ReadDotenv(writeTestFile(t, dir, "missing.env", "#include nope.env\n")); !os.IsNotExist(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReadDotenvCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.env", "A=1\n#include b.env\n")
+	writeTestFile(t, dir, "b.env", "B=1\n#include a.env\n")
+	_, err := ReadDotenv(a)
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	self := writeTestFile(t, dir, "self.env", "#include self.env\n")
+	if _, err := ReadDotenv(self); err == nil {
+		t.Fatal("expected an error")
+	}
+	// including the same file twice is not a cycle
+	writeTestFile(t, dir, "c.env", "C=1\n")
+	twice := writeTestFile(t, dir, "twice.env", "#include c.env\n#include c.env\n")
+	if _, err := ReadDotenv(twice); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDotenv(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, ".env", "A=env\nB=env\nC=env\nD=env\nE=env\n")
+	writeTestFile(t, dir, ".env.local", "B=local\nC=local\nD=local\nE=local\n")
+	writeTestFile(t, dir, ".env.test", "C=app\nD=app\n")
+	writeTestFile(t, dir, ".env.test.local", "D=app-local\n")
+	writeTestFile(t, dir, ".env.prod", "C=app\nD=app\n")
+	// .env.prod.local is missing
+
+	for _, c := range []struct {
+		appEnv string
+		exp    map[string]string
+	}{
+		{"", map[string]string{"A": "env", "B": "local", "C": "local", "D": "local", "E": "local"}},
+		{"prod", map[string]string{"A": "env", "B": "local", "C": "app", "D": "app", "E": "local"}},
+		// .env.local is skipped in tests
+		{"test", map[string]string{"A": "env", "B": "env", "C": "app", "D": "app-local", "E": "env"}},
+	} {
+		t.Run(c.appEnv, func(t *testing.T) {
+			testEnv(t, Map{"E": "process"})
+			d, err := LoadDotenv(dir, c.appEnv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := dotenvValues(d); !reflect.DeepEqual(got, c.exp) {
+				t.Fatalf("got %q, expected %q", got, c.exp)
+			}
+			// the source is added after the environment
+			if got := String("E", ""); got != "process" {
+				t.Fatalf("unexpected value: %q", got)
+			}
+			if got := String("A", ""); got != "env" {
+				t.Fatalf("unexpected value: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package env
+
+import (
+	"sort"
+	"strings"
+)
+
+type dotenvEntry struct {
+	raw string // original text, may span multiple lines
+	key string // empty for comments, blank lines and include directives
+	val string
+}
+
+// DotenvFile is a dotenv file that can be edited while preserving comments and formatting of unchanged lines.
+// It implements Source. Include directives are kept as is, but not followed.
+type DotenvFile struct {
+	entries []dotenvEntry
+}
+
+// ParseDotenvFile parses a dotenv file for editing. See ReadDotenv for the syntax.
+func ParseDotenvFile(data []byte) (*DotenvFile, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if text == "" {
+		lines = nil
+	}
+	f := &DotenvFile{}
+	next := 0 // index of the first line not added to entries
+	err := parseDotenv(text, "dotenv", func(line, n int, key, val string) error {
+		for ; next < line-1; next++ {
+			f.entries = append(f.entries, dotenvEntry{raw: lines[next]})
+		}
+		e := dotenvEntry{raw: strings.Join(lines[line-1:line-1+n], "\n"), key: key, val: val}
+		if key == "" {
+			e.val = ""
+		}
+		f.entries = append(f.entries, e)
+		next = line - 1 + n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for ; next < len(lines); next++ {
+		f.entries = append(f.entries, dotenvEntry{raw: lines[next]})
+	}
+	return f, nil
+}
+
+func (f *DotenvFile) index(key string) int {
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		if f.entries[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns a value of the variable. If the variable is defined multiple times, the last definition is used.
+func (f *DotenvFile) Get(key string) (string, bool) {
+	if i := f.index(key); i >= 0 {
+		return f.entries[i].val, true
+	}
+	return "", false
+}
+
+// Lookup implements Source.
+func (f *DotenvFile) Lookup(key string) (val, from string, ok bool) {
+	val, ok = f.Get(key)
+	return val, "dotenv", ok
+}
+
+// Keys implements Source.
+func (f *DotenvFile) Keys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, e := range f.entries {
+		if e.key != "" && !seen[e.key] {
+			seen[e.key] = true
+			keys = append(keys, e.key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set changes a value of the variable in place, or adds it to the end of the file.
+func (f *DotenvFile) Set(key, val string) {
+	i := f.index(key)
+	if i < 0 {
+		f.entries = append(f.entries, dotenvEntry{raw: key + "=" + quoteDotenv(val), key: key, val: val})
+		return
+	}
+	e := &f.entries[i]
+	prefix := ""
+	if strings.HasPrefix(strings.TrimSpace(e.raw), "export ") {
+		prefix = "export "
+	}
+	e.raw, e.val = prefix+key+"="+quoteDotenv(val), val
+}
+
+// Delete removes all definitions of the variable.
+func (f *DotenvFile) Delete(key string) {
+	out := f.entries[:0]
+	for _, e := range f.entries {
+		if e.key != key {
+			out = append(out, e)
+		}
+	}
+	f.entries = out
+}
+
+// Rename changes the name of the variable, keeping its value and formatting. An existing variable with the new name is removed.
+func (f *DotenvFile) Rename(key, name string) {
+	if key == name || f.index(key) < 0 {
+		return
+	}
+	f.Delete(name)
+	for i, e := range f.entries {
+		if e.key == key {
+			j := keyOffset(e.raw)
+			f.entries[i].raw = e.raw[:j] + name + e.raw[j+len(key):]
+			f.entries[i].key = name
+		}
+	}
+}
+
+// keyOffset returns the position of the key in a line, after the optional "export" prefix.
+func keyOffset(line string) int {
+	trimmed := strings.TrimLeft(line, " \t")
+	if rest, ok := strings.CutPrefix(trimmed, "export "); ok {
+		trimmed = strings.TrimLeft(rest, " \t")
+	}
+	return len(line) - len(trimmed)
+}
+
+// Clone returns a copy of the file.
+func (f *DotenvFile) Clone() *DotenvFile {
+	return &DotenvFile{entries: append([]dotenvEntry(nil), f.entries...)}
+}
+
+// Bytes returns the file contents.
+func (f *DotenvFile) Bytes() []byte {
+	var sb strings.Builder
+	for _, e := range f.entries {
+		sb.WriteString(e.raw)
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
@@ -0,0 +1,32 @@
+package env
+
+import "testing"
+
+func TestDotenvFileRename(t *testing.T) {
+	for _, c := range []struct {
+		in, exp string
+	}{
+		{"A=1\n", "B=1\n"},
+		{"  A = 1 # comment\n", "  B = 1 # comment\n"},
+		{"export A=1\n", "export B=1\n"},
+		{"export  A=1\n", "export  B=1\n"},
+		// the key is a part of the prefix
+		{"export port=1\n", "export PORT=1\n"},
+		{"export e=1\n", "export PORT=1\n"},
+		{"# A=0\nA=1\nA=2\n", "# A=0\nB=1\nB=2\n"},
+	} {
+		f, err := ParseDotenvFile([]byte(c.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		from := f.Keys()[0]
+		to := "B"
+		if from != "A" {
+			to = "PORT"
+		}
+		f.Rename(from, to)
+		if got := string(f.Bytes()); got != c.exp {
+			t.Errorf("%q: got %q, expected %q", c.in, got, c.exp)
+		}
+	}
+}
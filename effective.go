@@ -0,0 +1,94 @@
+package env
+
+import "sync"
+
+var (
+	lastErrMu sync.Mutex
+	lastErr   = make(map[string]error)
+)
+
+// recordError remembers the last error for the variable, see EffectiveVars.
+func recordError(key string, err error) {
+	lastErrMu.Lock()
+	lastErr[key] = err
+	lastErrMu.Unlock()
+}
+
+// logError records the error and reports it with Log.
+func logError(key string, err error) {
+	recordError(key, err)
+	Log(key, err)
+}
+
+// Effective describes the effective value of a variable.
+type Effective struct {
+	Var
+	Value  string // effective value; the default if the variable is not set
+	Set    bool   // the variable is set
+	From   string // variable or override that provided the value, see Value
+	Source string // where the value came from, see Value
+	Err    error  // the last error reported for the variable
+}
+
+// EffectiveVars returns effective values of all variables in the registry (see Vars), sorted by name.
+func EffectiveVars() []Effective {
+	vars := Vars()
+	out := make([]Effective, 0, len(vars))
+	// Lookup may record errors, so it must be called without holding lastErrMu
+	lastErrMu.Lock()
+	errs := make(map[string]error, len(lastErr))
+	for k, err := range lastErr {
+		errs[k] = err
+	}
+	lastErrMu.Unlock()
+	for _, v := range vars {
+		e := Effective{Var: v, Value: v.Default, Err: errs[v.Name]}
+		if cur, ok := Lookup(v.Name); ok {
+			e.Value, e.Set, e.From, e.Source = cur.Value, true, cur.From, cur.Source
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// Isolate makes all lookups use only the given source, ignoring the process environment and sources added with Use
+// or UseDefaults. It also starts with an empty registry of variables and instance overrides.
+// The returned function restores the previous state.
+//
+// It is intended for tests, and cannot be used concurrently with tests that read variables.
+func Isolate(src Source) (restore func()) {
+	srcMu.Lock()
+	oldNoEnv, oldSources, oldDefaults := noOSEnv, sources, defaults
+	noOSEnv, sources, defaults = true, []Source{src}, nil
+	srcMu.Unlock()
+
+	regMu.Lock()
+	oldRegistry := registry
+	registry = make(map[string]Var)
+	regMu.Unlock()
+
+	overMu.Lock()
+	oldOverrides := overrides
+	overrides = make(map[string][]override)
+	overMu.Unlock()
+
+	lastErrMu.Lock()
+	oldErr := lastErr
+	lastErr = make(map[string]error)
+	lastErrMu.Unlock()
+
+	return func() {
+		srcMu.Lock()
+		noOSEnv, sources, defaults = oldNoEnv, oldSources, oldDefaults
+		srcMu.Unlock()
+		regMu.Lock()
+		registry = oldRegistry
+		regMu.Unlock()
+		overMu.Lock()
+		overrides = oldOverrides
+		overMu.Unlock()
+		lastErrMu.Lock()
+		lastErr = oldErr
+		lastErrMu.Unlock()
+	}
+}
@@ -0,0 +1,26 @@
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffectiveVarsRecordsErrors(t *testing.T) {
+	restore := Isolate(Map{"A_FILE": "/nonexistent"})
+	defer restore()
+	oldLog := Log
+	Log = func(string, error) {}
+	defer func() { Log = oldLog }()
+
+	String("A", "")
+	done := make(chan []Effective)
+	go func() { done <- EffectiveVars() }()
+	select {
+	case vars := <-done:
+		if len(vars) != 1 || vars[0].Err == nil {
+			t.Fatalf("expected an error for A, got %+v", vars)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("EffectiveVars deadlocked")
+	}
+}
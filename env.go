@@ -1,10 +1,13 @@
 // Package env provides helpers for getting environment variables with a certain type.
+//
+// All getters find values with Lookup, so instance-specific overrides are taken into account,
+// and a variable that is not set can be read from a file pointed to by KEY_FILE.
 package env
 
 import (
 	"log"
-	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,20 +18,30 @@ var Log = func(key string, err error) {
 }
 
 // String gets a string variable from environment. It will use default if variable is empty.
+//
+// Instance-specific overrides and KEY_FILE are taken into account, see Lookup.
 func String(key string, def string) string {
-	if s := os.Getenv(key); s != "" {
-		return s
+	declare(key, "string", def)
+	if v, ok := Lookup(key); ok {
+		return v.Value
 	}
 	return def
 }
 
+// raw returns a raw value of the variable, or an empty string if it's not set.
+func raw(key string) string {
+	v, _ := Lookup(key)
+	return v.Value
+}
+
 // Bool gets a bool variable from environment. It will use default if variable is empty or in wrong format.
 func Bool(key string, def bool) bool {
-	if s := String(key, ""); s != "" {
+	declare(key, "bool", strconv.FormatBool(def))
+	if s := raw(key); s != "" {
 		if d, err := strconv.ParseBool(s); err == nil {
 			return d
 		} else {
-			Log(key, err)
+			logError(key, parseError(key, "bool", s, err))
 		}
 	}
 	return def
@@ -36,11 +49,12 @@ func Bool(key string, def bool) bool {
 
 // Int gets an int variable from environment. It will use default if variable is empty or in wrong format.
 func Int(key string, def int) int {
-	if s := String(key, ""); s != "" {
+	declare(key, "int", strconv.Itoa(def))
+	if s := raw(key); s != "" {
 		if d, err := strconv.Atoi(s); err == nil {
 			return d
 		} else {
-			Log(key, err)
+			logError(key, parseError(key, "int", s, err))
 		}
 	}
 	return def
@@ -48,11 +62,12 @@ func Int(key string, def int) int {
 
 // Float64 gets a float64 variable from environment. It will use default if variable is empty or in wrong format.
 func Float64(key string, def float64) float64 {
-	if s := String(key, ""); s != "" {
+	declare(key, "float64", strconv.FormatFloat(def, 'g', -1, 64))
+	if s := raw(key); s != "" {
 		if d, err := strconv.ParseFloat(s, 64); err == nil {
 			return d
 		} else {
-			Log(key, err)
+			logError(key, parseError(key, "float64", s, err))
 		}
 	}
 	return def
@@ -62,11 +77,76 @@ func Float64(key string, def float64) float64 {
 //
 // Duration uses time.ParseDuration, so format must follow its rules.
 func Duration(key string, def time.Duration) time.Duration {
-	if s := String(key, ""); s != "" {
+	declare(key, "duration", def.String())
+	if s := raw(key); s != "" {
 		if d, err := time.ParseDuration(s); err == nil {
 			return d
 		} else {
-			Log(key, err)
+			logError(key, parseError(key, "duration", s, err))
+		}
+	}
+	return def
+}
+
+// Bytes gets a size in bytes from environment. It will use default if variable is empty or in wrong format.
+//
+// See ParseBytes for supported formats.
+func Bytes(key string, def int64) int64 {
+	declare(key, "bytes", strconv.FormatInt(def, 10))
+	if s := raw(key); s != "" {
+		if d, err := ParseBytes(s); err == nil {
+			return d
+		} else {
+			logError(key, parseError(key, "bytes", s, err))
+		}
+	}
+	return def
+}
+
+// Strings gets a comma-separated list of strings from environment. It will use default if variable is empty.
+//
+// Spaces around elements are removed, and empty elements are skipped.
+func Strings(key string, def []string) []string {
+	declare(key, "strings", strings.Join(def, ","))
+	if s := raw(key); s != "" {
+		var out []string
+		for _, p := range strings.Split(s, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+	return def
+}
+
+// Milli gets a Kubernetes resource quantity in milli-units from environment, like a CPU request ("500m" is 500, "2" is 2000).
+// It will use default if variable is empty or in wrong format.
+//
+// See ParseQuantity for supported formats.
+func Milli(key string, def int64) int64 {
+	declare(key, "milli", FormatQuantity(def, false))
+	if s := raw(key); s != "" {
+		if d, err := ParseQuantity(s); err == nil {
+			return d
+		} else {
+			logError(key, parseError(key, "milli", s, err))
+		}
+	}
+	return def
+}
+
+// Quantity gets a Kubernetes resource quantity from environment as an integer, rounded up, like a memory limit in bytes
+// ("256Mi" is 268435456, "1G" is 1000000000). It will use default if variable is empty or in wrong format.
+//
+// See ParseQuantity for supported formats.
+func Quantity(key string, def int64) int64 {
+	declare(key, "quantity", FormatQuantity(def*1000, true))
+	if s := raw(key); s != "" {
+		if d, err := parseQuantityValue(s); err == nil {
+			return d
+		} else {
+			logError(key, parseError(key, "quantity", s, err))
 		}
 	}
 	return def
@@ -0,0 +1,279 @@
+// Package envgen generates typed configuration accessors from a spec of environment variables.
+package envgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dennwc/env"
+)
+
+type goType struct {
+	name   string // Go type
+	getter string // getter function in env package
+}
+
+var types = map[string]goType{
+	"string":   {"string", "String"},
+	"bool":     {"bool", "Bool"},
+	"int":      {"int", "Int"},
+	"float64":  {"float64", "Float64"},
+	"duration": {"time.Duration", "Duration"},
+	"bytes":    {"int64", "Bytes"},
+	"strings":  {"[]string", "Strings"},
+	"milli":    {"int64", "Milli"},
+	"quantity": {"int64", "Quantity"},
+}
+
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "DB": true, "DNS": true, "GC": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true,
+	"TTL": true, "UDP": true, "UI": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// GoName converts a variable name like "HTTP_PORT" to a Go identifier like "HTTPPort".
+func GoName(key string) string {
+	var sb strings.Builder
+	for _, w := range strings.FieldsFunc(key, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	}) {
+		w = strings.ToUpper(w)
+		if initialisms[w] {
+			sb.WriteString(w)
+			continue
+		}
+		sb.WriteString(w[:1] + strings.ToLower(w[1:]))
+	}
+	s := sb.String()
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "V" + s
+	}
+	return s
+}
+
+// Generate writes a Go file with a configuration struct for the spec, a loader using env getters and validation.
+// The loader returns errors for values in a wrong format, missing required variables and violated constraints.
+func Generate(w io.Writer, spec *env.Spec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	pkg, name := spec.Package, spec.Struct
+	if pkg == "" {
+		pkg = "config"
+	}
+	if name == "" {
+		name = "Config"
+	}
+	var buf bytes.Buffer
+	p := func(format string, args ...any) {
+		fmt.Fprintf(&buf, format, args...)
+	}
+	p("// %s is a configuration loaded from environment variables.\ntype %s struct {\n", name, name)
+	for _, v := range spec.Vars {
+		if v.Description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(v.Description), "\n") {
+				p("\t// %s\n", line)
+			}
+		}
+		p("\t%s %s // %s\n", GoName(v.Name), types[v.Type].name, v.Name)
+	}
+	p("}\n\n")
+
+	p("func init() {\n")
+	for _, v := range spec.Vars {
+		p("\tenv.Declare(env.Var{Name: %q, Type: %q", v.Name, v.Type)
+		if v.Default != "" {
+			p(", Default: %q", v.Default)
+		}
+		if v.Description != "" {
+			p(", Description: %q", v.Description)
+		}
+		if v.Secret {
+			p(", Secret: true")
+		}
+		if v.Required {
+			p(", Required: true")
+		}
+		if v.Min != nil {
+			p(", Min: &[]float64{%v}[0]", *v.Min)
+		}
+		if v.Max != nil {
+			p(", Max: &[]float64{%v}[0]", *v.Max)
+		}
+		if len(v.Enum) != 0 {
+			p(", Enum: %#v", v.Enum)
+		}
+		p("})\n")
+	}
+	p("}\n\n")
+
+	p("// Load%s reads %s from environment variables and validates it.\n", name, name)
+	p("func Load%s() (*%s, error) {\n\tc := &%s{\n", name, name, name)
+	for _, v := range spec.Vars {
+		def, err := literal(v)
+		if err != nil {
+			return fmt.Errorf("variable %s: %w", v.Name, err)
+		}
+		p("\t\t%s: env.%s(%q, %s),\n", GoName(v.Name), types[v.Type].getter, v.Name, def)
+	}
+	p("\t}\n\tvar errs []error\n")
+	// getters fall back to defaults on invalid values, so values are parsed again to report errors
+	var typed []string
+	for _, v := range spec.Vars {
+		if v.Type != "string" {
+			typed = append(typed, fmt.Sprintf("{%q, %q}", v.Name, v.Type))
+		}
+	}
+	if len(typed) != 0 {
+		p("\tfor _, v := range []struct{ key, typ string }{\n\t\t%s,\n\t} {\n", strings.Join(typed, ",\n\t\t"))
+		p("\t\tif cur, ok := env.Lookup(v.key); ok {\n\t\t\tif _, err := env.Parse(v.key, v.typ, cur.Value); err != nil {\n")
+		p("\t\t\t\terrs = append(errs, fmt.Errorf(\"variable %%s: %%w\", v.key, err))\n\t\t\t}\n\t\t}\n\t}\n")
+	}
+	var required []string
+	for _, v := range spec.Vars {
+		if v.Required {
+			required = append(required, strconv.Quote(v.Name))
+		}
+	}
+	if len(required) != 0 {
+		p("\tfor _, key := range []string{%s} {\n", strings.Join(required, ", "))
+		p("\t\tif _, ok := env.Lookup(key); !ok {\n\t\t\terrs = append(errs, fmt.Errorf(\"variable %%s is required\", key))\n\t\t}\n\t}\n")
+	}
+	p("\tif err := c.Validate(); err != nil {\n\t\terrs = append(errs, err)\n\t}\n")
+	p("\treturn c, errors.Join(errs...)\n}\n\n")
+
+	p("// Validate checks constraints of %s.\n", name)
+	p("func (c *%s) Validate() error {\n\tvar errs []error\n", name)
+	for _, v := range spec.Vars {
+		field := "c." + GoName(v.Name)
+		num := field
+		switch v.Type {
+		case "duration":
+			num = field + ".Seconds()"
+		case "int", "bytes", "milli", "quantity":
+			num = "float64(" + field + ")"
+		case "float64":
+		default:
+			num = ""
+		}
+		if num != "" && v.Min != nil {
+			p("\tif %s < %v {\n\t\terrs = append(errs, fmt.Errorf(\"variable %s must be at least %v, got %%v\", %s))\n\t}\n", num, *v.Min, v.Name, *v.Min, field)
+		}
+		if num != "" && v.Max != nil {
+			p("\tif %s > %v {\n\t\terrs = append(errs, fmt.Errorf(\"variable %s must be at most %v, got %%v\", %s))\n\t}\n", num, *v.Max, v.Name, *v.Max, field)
+		}
+		if len(v.Enum) != 0 {
+			typ := v.Type
+			if typ == "strings" {
+				// each element is checked separately
+				typ = "string"
+				p("\tfor _, s := range %s {\n", field)
+				field = "s"
+			}
+			var cases []string
+			for _, e := range v.Enum {
+				lit, err := literal(env.Var{Type: typ, Default: e})
+				if err != nil {
+					return fmt.Errorf("variable %s: enum: %w", v.Name, err)
+				}
+				cases = append(cases, lit)
+			}
+			p("\tswitch %s {\n\tcase %s:\n\tdefault:\n", field, strings.Join(cases, ", "))
+			p("\t\terrs = append(errs, fmt.Errorf(\"variable %s must be one of %s, got %%v\", %s))\n\t}\n", v.Name, strings.Join(v.Enum, ", "), field)
+			if v.Type == "strings" {
+				p("\t}\n")
+			}
+		}
+	}
+	p("\treturn errors.Join(errs...)\n}\n")
+
+	body := buf.String()
+	buf.Reset()
+	p("// Code generated by envgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n\t\"errors\"\n", pkg)
+	for _, imp := range []string{"fmt", "time"} {
+		if strings.Contains(body, imp+".") {
+			p("\t%q\n", imp)
+		}
+	}
+	p("\n\t\"github.com/dennwc/env\"\n)\n\n")
+	buf.WriteString(body)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("cannot format generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// literal returns a Go literal for the default value of the variable.
+func literal(v env.Var) (string, error) {
+	s := v.Default
+	switch v.Type {
+	case "string":
+		return strconv.Quote(s), nil
+	case "bool":
+		if s == "" {
+			return "false", nil
+		}
+		b, err := strconv.ParseBool(s)
+		return strconv.FormatBool(b), err
+	case "int":
+		if s == "" {
+			return "0", nil
+		}
+		n, err := strconv.Atoi(s)
+		return strconv.Itoa(n), err
+	case "float64":
+		if s == "" {
+			return "0", nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return strconv.FormatFloat(f, 'g', -1, 64), err
+	case "bytes", "milli", "quantity":
+		if s == "" {
+			return "0", nil
+		}
+		n, err := env.Parse(v.Name, v.Type, s)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprint(n), nil
+	case "duration":
+		if s == "" {
+			return "0", nil
+		}
+		d, err := time.ParseDuration(s)
+		return durationLiteral(d), err
+	case "strings":
+		v, err := env.Parse(v.Name, v.Type, s)
+		if err != nil || s == "" {
+			return "nil", err
+		}
+		return fmt.Sprintf("%#v", v), nil
+	}
+	return "", fmt.Errorf("unsupported type %q", v.Type)
+}
+
+func durationLiteral(d time.Duration) string {
+	for _, u := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	} {
+		if d != 0 && d%u.d == 0 {
+			return strconv.FormatInt(int64(d/u.d), 10) + " * " + u.name
+		}
+	}
+	return "time.Duration(" + strconv.FormatInt(int64(d), 10) + ")"
+}
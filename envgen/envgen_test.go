@@ -0,0 +1,126 @@
+package envgen
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dennwc/env"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func generate(t *testing.T) []byte {
+	t.Helper()
+	spec, err := env.ReadSpec("testdata/config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Generate(&buf, spec); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateGolden(t *testing.T) {
+	got := generate(t)
+	const golden = "testdata/config.go.golden"
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	exp, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(exp, got) {
+		t.Fatalf("generated code differs from %s (run tests with -update to accept):\n%s", golden, got)
+	}
+}
+
+const mainSrc = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"example.com/gen/config"
+	"github.com/dennwc/env"
+)
+
+func main() {
+	env.Log = func(string, error) {}
+	c, err := config.LoadConfig()
+	fmt.Printf("%d %s %v\n", c.HTTPPort, c.LogLevel, c.Timeout)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+`
+
+// TestGeneratedCode checks that the generated code compiles and reports invalid values.
+func TestGeneratedCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not available")
+	}
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/gen\n\ngo 1.21\n\nrequire github.com/dennwc/env v0.0.0\n\nreplace github.com/dennwc/env => " + root + "\n",
+		"config/config.go": string(generate(t)),
+		"main.go":          mainSrc,
+	}
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bin := filepath.Join(dir, "gen")
+	cmd := exec.Command(goBin, "build", "-o", bin, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not compile: %v\n%s", err, out)
+	}
+	for _, c := range []struct {
+		name string
+		env  []string
+		out  string
+		fail bool
+	}{
+		{name: "valid", env: []string{"DB_PASSWORD=x", "HTTP_PORT=80"}, out: "80 info 1m30s\n"},
+		{name: "invalid int", env: []string{"DB_PASSWORD=x", "HTTP_PORT=abc"}, out: "HTTP_PORT", fail: true},
+		{name: "invalid duration", env: []string{"DB_PASSWORD=x", "TIMEOUT=5"}, out: "TIMEOUT", fail: true},
+		{name: "missing required", out: "DB_PASSWORD", fail: true},
+		{name: "enum", env: []string{"DB_PASSWORD=x", "FEATURES=a,c"}, out: "FEATURES", fail: true},
+		{name: "min", env: []string{"DB_PASSWORD=x", "HTTP_PORT=0"}, out: "HTTP_PORT", fail: true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := exec.Command(bin)
+			cmd.Env = c.env
+			out, err := cmd.CombinedOutput()
+			if (err != nil) != c.fail || !strings.Contains(string(out), c.out) {
+				t.Fatalf("unexpected result: %v\n%s", err, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package envserve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dennwc/env"
+)
+
+// Fetch reads all variables of the namespace from a server at baseURL (for example, "http://localhost:8500").
+// The result can be added as a source with env.Use.
+func Fetch(ctx context.Context, baseURL, ns string) (env.Map, error) {
+	u := strings.TrimSuffix(baseURL, "/") + "/v1/kv/" + url.PathEscape(ns)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("envserve: %s: %s", u, resp.Status)
+	}
+	var r Response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	return env.Map(r.Values), nil
+}
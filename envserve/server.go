@@ -0,0 +1,309 @@
+// Package envserve serves dotenv and JSON files from a directory as a versioned key-value HTTP API.
+//
+// Each file in the directory is a namespace named after the file without extension ("app.env" is "app",
+// and ".env" is "default"). Files that cannot be read, and files with the same namespace name ("app.env" and
+// "app.json") are reported with Log and skipped, keeping the previously served values.
+//
+// Versions of namespaces start from the time the server was started (in seconds since the Unix epoch)
+// and are incremented on every change, so they keep increasing when the server is restarted.
+//
+// The API is:
+//
+//	GET /v1/namespaces          - list of namespaces with their versions
+//	GET /v1/kv/<ns>             - all variables of the namespace: {"version": 1700000003, "values": {"KEY": "value"}}
+//	GET /v1/kv/<ns>?prefix=DB_  - only variables with a given prefix
+//	GET /v1/kv/<ns>/<key>       - raw value of a single variable
+//
+// Responses for namespaces have an ETag, and requests with a matching If-None-Match header get 304 Not Modified.
+// Adding "wait=30s" to the query makes the request block until the namespace changes (compared to If-None-Match
+// or the "version" query parameter) or the timeout expires. This allows clients to watch for changes.
+package envserve
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dennwc/env"
+)
+
+// MaxWait is the maximal duration of a long-polling request.
+const MaxWait = 5 * time.Minute
+
+// Log is a function for reporting files that cannot be served.
+var Log = func(file string, err error) {
+	log.Printf("envserve: skipping %s: %v", file, err)
+}
+
+type namespace struct {
+	version int
+	etag    string
+	values  map[string]string
+}
+
+// Server serves files from a directory. It implements http.Handler.
+type Server struct {
+	dir   string
+	epoch int // first version of namespaces
+
+	failed map[string]string // errors reported for files, to report each of them once
+
+	mu      sync.RWMutex
+	ns      map[string]*namespace
+	changed chan struct{} // closed and replaced on every change
+}
+
+// New creates a server for the directory and reads all files from it.
+func New(dir string) (*Server, error) {
+	s := &Server{dir: dir, epoch: int(time.Now().Unix()), ns: make(map[string]*namespace), changed: make(chan struct{})}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reads all files from the directory and increments versions of namespaces that have changed.
+func (s *Server) Reload() error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"))
+	if err != nil {
+		return err
+	}
+	cur := make(map[string]map[string]string)
+	paths := make(map[string]string)
+	skip := make(map[string]bool) // namespaces that keep previous values
+	failed := make(map[string]string)
+	fail := func(file string, err error) {
+		failed[file] = err.Error()
+		if s.failed[file] != failed[file] {
+			Log(file, err)
+		}
+	}
+	for _, file := range files {
+		st, err := os.Stat(file)
+		if err != nil || st.IsDir() {
+			continue
+		}
+		name := filepath.Base(file)
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		if name == "" {
+			// ".env" file
+			name = "default"
+		}
+		if prev, ok := paths[name]; ok {
+			fail(file, fmt.Errorf("namespace %q is already served from %s", name, filepath.Base(prev)))
+			delete(cur, name)
+			skip[name] = true
+			continue
+		}
+		paths[name] = file
+		if skip[name] {
+			continue
+		}
+		d, err := env.ReadDotenv(file)
+		if err != nil {
+			fail(file, err)
+			skip[name] = true
+			continue
+		}
+		vals := make(map[string]string)
+		for _, k := range d.Keys() {
+			vals[k], _, _ = d.Lookup(k)
+		}
+		cur[name] = vals
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = failed
+	changed := false
+	for name, vals := range cur {
+		etag := hashValues(vals)
+		ns := s.ns[name]
+		if ns == nil {
+			ns = &namespace{version: s.epoch - 1}
+			s.ns[name] = ns
+		} else if ns.etag == etag {
+			continue
+		}
+		ns.version++
+		ns.etag, ns.values = etag, vals
+		changed = true
+	}
+	for name, ns := range s.ns {
+		if _, ok := cur[name]; !ok && !skip[name] && ns.values != nil {
+			// keep the version, so clients see a change if the file is added again
+			ns.version++
+			ns.etag, ns.values = hashValues(nil), nil
+			changed = true
+		}
+	}
+	if changed {
+		close(s.changed)
+		s.changed = make(chan struct{})
+	}
+	return nil
+}
+
+func hashValues(vals map[string]string) string {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%q=%q\n", k, vals[k])
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// Run reloads files from the directory with a given interval, until the context is cancelled.
+func (s *Server) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			if err := s.Reload(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// snapshot returns the current state of the namespace and a channel that is closed on the next change.
+func (s *Server) snapshot(name string) (namespace, bool, <-chan struct{}) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ns, ok := s.ns[name]
+	if !ok || ns.values == nil {
+		return namespace{}, false, s.changed
+	}
+	return *ns, true, s.changed
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch p := r.URL.Path; {
+	case p == "/v1/namespaces":
+		s.serveNamespaces(w)
+	case strings.HasPrefix(p, "/v1/kv/"):
+		name, key, _ := strings.Cut(strings.TrimPrefix(p, "/v1/kv/"), "/")
+		s.serveKV(w, r, name, key)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveNamespaces(w http.ResponseWriter) {
+	s.mu.RLock()
+	out := make(map[string]int)
+	for name, ns := range s.ns {
+		if ns.values != nil {
+			out[name] = ns.version
+		}
+	}
+	s.mu.RUnlock()
+	writeJSON(w, out)
+}
+
+func (s *Server) serveKV(w http.ResponseWriter, r *http.Request, name, key string) {
+	q := r.URL.Query()
+	var wait time.Duration
+	if v := q.Get("wait"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			http.Error(w, "invalid wait duration", http.StatusBadRequest)
+			return
+		}
+		wait = min(d, MaxWait)
+	}
+	known := -1
+	if v := q.Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid version", http.StatusBadRequest)
+			return
+		}
+		known = n
+	}
+	etag := r.Header.Get("If-None-Match")
+	unchanged := func(ns namespace) bool {
+		return (etag != "" && etag == ns.etag) || (known >= 0 && ns.version <= known)
+	}
+	ns, ok, changed := s.snapshot(name)
+	if wait > 0 && (!ok || unchanged(ns)) {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+	loop:
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-timer.C:
+				break loop
+			case <-changed:
+				ns, ok, changed = s.snapshot(name)
+				if ok && !unchanged(ns) {
+					break loop
+				}
+			}
+		}
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("ETag", ns.etag)
+	w.Header().Set("X-Config-Version", strconv.Itoa(ns.version))
+	if unchanged(ns) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if key != "" {
+		v, ok := ns.values[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(v))
+		return
+	}
+	vals := ns.values
+	if prefix := q.Get("prefix"); prefix != "" {
+		vals = make(map[string]string)
+		for k, v := range ns.values {
+			if strings.HasPrefix(k, prefix) {
+				vals[k] = v
+			}
+		}
+	}
+	writeJSON(w, Response{Version: ns.version, Values: vals})
+}
+
+// Response is a response for a namespace.
+type Response struct {
+	Version int               `json:"version"`
+	Values  map[string]string `json:"values"`
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
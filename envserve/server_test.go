@@ -0,0 +1,99 @@
+package envserve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func logged(t *testing.T) *[]string {
+	var files []string
+	old := Log
+	Log = func(file string, err error) { files = append(files, filepath.Base(file)) }
+	t.Cleanup(func() { Log = old })
+	return &files
+}
+
+func TestReloadSkipsInvalidFiles(t *testing.T) {
+	files := logged(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "app.env", "A=1\n")
+	writeFile(t, dir, "bad.json", "{")
+	s, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.snapshot("app"); !ok {
+		t.Fatal("valid file is not served")
+	}
+	if _, ok, _ := s.snapshot("bad"); ok {
+		t.Fatal("invalid file is served")
+	}
+	// the file becomes invalid: previous values are kept
+	writeFile(t, dir, "app.env", "A='1\n")
+	for i := 0; i < 2; i++ {
+		if err := s.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ns, ok, _ := s.snapshot("app"); !ok || ns.values["A"] != "1" {
+		t.Fatalf("previous values are not kept: %+v", ns)
+	}
+	if len(*files) != 2 {
+		t.Fatalf("expected each error to be reported once, got %v", *files)
+	}
+}
+
+func TestReloadRejectsCollisions(t *testing.T) {
+	files := logged(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "app.env", "A=1\n")
+	writeFile(t, dir, "app.json", `{"A": "2"}`)
+	s, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns, ok, _ := s.snapshot("app"); ok {
+		t.Fatalf("ambiguous namespace is served: %+v", ns)
+	}
+	if len(*files) != 1 || (*files)[0] != "app.json" {
+		t.Fatalf("unexpected reports: %v", *files)
+	}
+	os.Remove(filepath.Join(dir, "app.json"))
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if ns, ok, _ := s.snapshot("app"); !ok || ns.values["A"] != "1" {
+		t.Fatalf("unexpected values: %+v", ns)
+	}
+}
+
+func TestVersionsStartFromEpoch(t *testing.T) {
+	logged(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "app.env", "A=1\n")
+	start := int(time.Now().Unix())
+	s, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns, _, _ := s.snapshot("app")
+	if ns.version < start {
+		t.Fatalf("version %d is less than the start time %d", ns.version, start)
+	}
+	writeFile(t, dir, "app.env", "A=2\n")
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if ns2, _, _ := s.snapshot("app"); ns2.version != ns.version+1 {
+		t.Fatalf("unexpected version after a change: %d", ns2.version)
+	}
+}
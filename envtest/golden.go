@@ -0,0 +1,134 @@
+// Package envtest provides helpers for testing configuration read with the env package.
+package envtest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dennwc/env"
+)
+
+// update is namespaced, so that it does not conflict with the -update flag that tests commonly define.
+var update = flag.Bool("envtest.update", false, "update golden files of envtest.Golden")
+
+// updating reports if golden files should be written: with -envtest.update, or with -update if the test defines it.
+func updating() bool {
+	if *update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		b, _ := strconv.ParseBool(f.Value.String())
+		return b
+	}
+	return false
+}
+
+// Golden runs load with variables from the fixture as the only environment and compares effective configuration
+// with the golden file. The golden file lists every variable that was read or declared by load, with its type,
+// default, effective value, the place the value came from and the last reported error.
+//
+// Run tests with the -envtest.update flag (or -update, if the test package defines it) to write golden files
+// instead of comparing them.
+//
+// Golden uses env.Isolate, so tests calling it must not run in parallel with other tests reading variables.
+func Golden(t testing.TB, golden string, fixture map[string]string, load func() error) {
+	t.Helper()
+	restore := env.Isolate(env.Map(fixture))
+	defer restore()
+	err := load()
+	got := Format(env.EffectiveVars())
+	if err != nil {
+		got = append(got, fmt.Sprintf("\nload error:\n%s\n", indent(err.Error()))...)
+	}
+	compare(t, golden, got)
+}
+
+// Format formats effective values in the format used by Golden.
+func Format(vars []env.Effective) []byte {
+	var buf bytes.Buffer
+	for i, v := range vars {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, "%s (%s)\n", v.Name, v.Type)
+		if v.Default != "" {
+			fmt.Fprintf(&buf, "  default: %q\n", secret(v.Var, v.Default))
+		}
+		if v.Set {
+			fmt.Fprintf(&buf, "  value: %q (%s from %s)\n", secret(v.Var, v.Value), v.From, v.Source)
+		}
+		if v.Err != nil {
+			fmt.Fprintf(&buf, "  error: %s\n", v.Err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func secret(v env.Var, s string) string {
+	if v.Secret {
+		return "(secret)"
+	}
+	return s
+}
+
+func indent(s string) string {
+	return "  " + strings.ReplaceAll(s, "\n", "\n  ")
+}
+
+func compare(t testing.TB, golden string, got []byte) {
+	t.Helper()
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	exp, err := os.ReadFile(golden)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist; run tests with -envtest.update to create it", golden)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(exp, got) {
+		t.Errorf("effective configuration differs from %s (run tests with -envtest.update to accept):\n%s", golden, diff(string(exp), string(got)))
+	}
+}
+
+// diff returns a simple line diff between the expected and actual text.
+func diff(exp, got string) string {
+	a, b := strings.Split(exp, "\n"), strings.Split(got, "\n")
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && !contains(b[j:], a[i]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
+
+func contains(lines []string, s string) bool {
+	for _, l := range lines {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package envtest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dennwc/env"
+)
+
+// update is defined the same way as in many tests, and must not conflict with the flag of the package.
+var _ = flag.Bool("update", false, "update golden files")
+
+// recorder is a testing.TB that records failures instead of failing the test. It must run in its own goroutine,
+// see record.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// record runs the function and returns failures it reported.
+func record(t *testing.T, fnc func(tb testing.TB)) []string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fnc(r)
+	}()
+	<-done
+	return r.errs
+}
+
+func TestFormat(t *testing.T) {
+	got := Format([]env.Effective{
+		{Var: env.Var{Name: "PORT", Type: "int", Default: "80"}, Value: "81", Set: true, From: "PORT", Source: "map"},
+		{Var: env.Var{Name: "TOKEN", Type: "string", Default: "dev", Secret: true}, Value: "prod", Set: true, From: "TOKEN", Source: "env"},
+		{Var: env.Var{Name: "DEBUG", Type: "bool"}, Err: errors.New("bad value")},
+	})
+	exp := `PORT (int)
+  default: "80"
+  value: "81" (PORT from map)
+
+TOKEN (string)
+  default: "(secret)"
+  value: "(secret)" (TOKEN from env)
+
+DEBUG (bool)
+  error: bad value
+`
+	if string(got) != exp {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	got := diff("a\nb\nc\n", "a\nx\nc\nd\n")
+	if exp := "- b\n+ x\n+ d\n"; got != exp {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+	if got := diff("a\n", "a\n"); got != "" {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "config.golden")
+	load := func() error {
+		env.String("NAME", "app")
+		env.Int("PORT", 80)
+		return nil
+	}
+	fixture := map[string]string{"PORT": "81"}
+
+	errs := record(t, func(tb testing.TB) { Golden(tb, golden, fixture, load) })
+	if len(errs) != 1 || !strings.Contains(errs[0], "does not exist") {
+		t.Fatalf("unexpected errors: %q", errs)
+	}
+
+	exp := "NAME (string)\n  default: \"app\"\n\nPORT (int)\n  default: \"80\"\n  value: \"81\" (PORT from map)\n"
+	if err := os.WriteFile(golden, []byte(exp), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if errs := record(t, func(tb testing.TB) { Golden(tb, golden, fixture, load) }); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %q", errs)
+	}
+
+	errs = record(t, func(tb testing.TB) {
+		Golden(tb, golden, map[string]string{"PORT": "82"}, func() error {
+			load()
+			return errors.New("failed")
+		})
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0], `+   value: "82"`) || !strings.Contains(errs[0], "+   failed") {
+		t.Fatalf("unexpected errors: %q", errs)
+	}
+}
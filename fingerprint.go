@@ -0,0 +1,55 @@
+package env
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Fingerprint returns a stable hash of the effective configuration. It can be exposed as a metric label
+// or in a health endpoint to detect configuration drift between replicas.
+//
+// All variables from the registry (see Vars) are hashed in sorted order, with values normalized the same way
+// as Normalize does, so "1m" and "60s" produce the same fingerprint. Unset variables use their defaults.
+// Values of secret variables are never hashed directly: an HMAC-SHA256 of the value keyed with secretKey is used instead.
+// Replicas must share the same secretKey for their fingerprints to be comparable.
+//
+// An unkeyed hash of a low-entropy secret can be brute-forced, so if secretKey is empty, values of secret variables
+// are not used at all, and only whether they are set affects the fingerprint.
+//
+// The result is a hex-encoded SHA-256 hash.
+func Fingerprint(secretKey []byte) string {
+	sum := sha256.Sum256(fingerprintData(secretKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// fingerprintData returns the data hashed by Fingerprint.
+func fingerprintData(secretKey []byte) []byte {
+	var buf bytes.Buffer
+	for _, v := range Vars() {
+		s := v.Default
+		if cur, ok := Lookup(v.Name); ok {
+			s = cur.Value
+		}
+		if s != "" && knownType(v.Type) {
+			if n, err := Normalize(v.Name, v.Type, s); err == nil {
+				s = n
+			} else {
+				s = "invalid:" + s
+			}
+		}
+		switch {
+		case !v.Secret:
+		case len(secretKey) == 0 && s != "":
+			s = "set"
+		case len(secretKey) != 0:
+			m := hmac.New(sha256.New, secretKey)
+			m.Write([]byte(s))
+			s = "hmac:" + hex.EncodeToString(m.Sum(nil))
+		}
+		fmt.Fprintf(&buf, "%q=%q\n", v.Name, s)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,64 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+)
+
+func fingerprintOf(t *testing.T, vals Map, key string) string {
+	t.Helper()
+	testEnv(t, vals)
+	Declare(Var{Name: "TIMEOUT", Type: "duration", Default: "30s"})
+	Declare(Var{Name: "PORT", Type: "int"})
+	Declare(Var{Name: "TOKEN", Type: "string", Secret: true})
+	return Fingerprint([]byte(key))
+}
+
+func TestFingerprint(t *testing.T) {
+	base := fingerprintOf(t, Map{"TIMEOUT": "1m", "PORT": "80", "TOKEN": "secret"}, "key")
+	if len(base) != 64 {
+		t.Fatalf("unexpected fingerprint: %q", base)
+	}
+	for _, c := range []struct {
+		name string
+		vals Map
+		key  string
+		same bool
+	}{
+		{name: "equivalent value", vals: Map{"TIMEOUT": "60s", "PORT": "80", "TOKEN": "secret"}, key: "key", same: true},
+		{name: "different value", vals: Map{"TIMEOUT": "2m", "PORT": "80", "TOKEN": "secret"}, key: "key"},
+		{name: "default", vals: Map{"TIMEOUT": "30s", "PORT": "80", "TOKEN": "secret"}, key: "key"},
+		{name: "different secret", vals: Map{"TIMEOUT": "1m", "PORT": "80", "TOKEN": "other"}, key: "key"},
+		{name: "different key", vals: Map{"TIMEOUT": "1m", "PORT": "80", "TOKEN": "secret"}, key: "other"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := fingerprintOf(t, c.vals, c.key); (got == base) != c.same {
+				t.Fatalf("unexpected fingerprint: %s vs %s", got, base)
+			}
+		})
+	}
+	// defaults are used for unset variables
+	if a, b := fingerprintOf(t, Map{"PORT": "80"}, "key"), fingerprintOf(t, Map{"TIMEOUT": "30s", "PORT": "80"}, "key"); a != b {
+		t.Fatal("unset variable differs from its default")
+	}
+}
+
+func TestFingerprintSecrets(t *testing.T) {
+	testEnv(t, Map{"TOKEN": "hunter2"})
+	Declare(Var{Name: "TOKEN", Type: "string", Secret: true})
+	for _, key := range []string{"key", ""} {
+		if data := fingerprintData([]byte(key)); bytes.Contains(data, []byte("hunter2")) {
+			t.Fatalf("secret value is hashed directly: %q", data)
+		}
+	}
+	// without a key, secret values do not affect the fingerprint
+	a := Fingerprint(nil)
+	testEnv(t, Map{"TOKEN": "other"})
+	Declare(Var{Name: "TOKEN", Type: "string", Secret: true})
+	if b := Fingerprint(nil); a != b {
+		t.Fatal("unkeyed fingerprint depends on the secret value")
+	}
+	if a == Fingerprint([]byte("key")) {
+		t.Fatal("keyed fingerprint must differ")
+	}
+}
@@ -0,0 +1,59 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ReadFS reads dotenv or JSON files from the file system, for example from embed.FS.
+// Variables from later files override the ones from earlier files.
+//
+// Files with ".json" extension must contain a single object with variable names as keys.
+// Other files are parsed as dotenv files (see ReadDotenv), and include directives are resolved within fsys.
+//
+// The result is usually passed to UseDefaults to ship default configuration inside the binary:
+//
+//	//go:embed defaults.env
+//	var defaultsFS embed.FS
+//
+//	d, err := env.ReadFS(defaultsFS, "defaults.env")
+//	if err != nil {
+//		panic(err)
+//	}
+//	env.UseDefaults(d)
+func ReadFS(fsys fs.FS, files ...string) (*Dotenv, error) {
+	l := newDotenvLoader(func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	}, path.Dir, path.Join, func(string) bool { return false })
+	for _, f := range files {
+		if err := l.load(f); err != nil {
+			return nil, err
+		}
+	}
+	return l.d, nil
+}
+
+// parseJSONVars parses a JSON object with variables and calls fnc for each of them.
+// Non-string values are converted to their JSON representation.
+func parseJSONVars(data []byte, file string, fnc func(key, val string)) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	for k, v := range m {
+		if bytes.Equal(v, []byte("null")) {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			fnc(k, s)
+			continue
+		}
+		fnc(k, strings.TrimSpace(string(v)))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package env
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/defaults.env": {Data: []byte("#include base.env\nPORT=80\n")},
+		"conf/base.env":     {Data: []byte("PORT=1\nNAME=base\n")},
+		"conf/extra.json":   {Data: []byte(`{"NAME": "json", "LIMIT": 5, "EMPTY": null}`)},
+		"loop.env":          {Data: []byte("#include loop.env\n")},
+	}
+	d, err := ReadFS(fsys, "conf/defaults.env", "conf/extra.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		key, exp, from string
+	}{
+		{"PORT", "80", "conf/defaults.env:2"},
+		{"NAME", "json", "conf/extra.json"},
+		{"LIMIT", "5", "conf/extra.json"},
+	} {
+		if v, from, ok := d.Lookup(c.key); !ok || v != c.exp || from != c.from {
+			t.Errorf("%s: unexpected value %q from %q", c.key, v, from)
+		}
+	}
+	if _, _, ok := d.Lookup("EMPTY"); ok {
+		t.Error("null values must be skipped")
+	}
+	if _, err := ReadFS(fsys, "loop.env"); err == nil {
+		t.Error("expected an include cycle error")
+	}
+	if _, err := ReadFS(fsys, "missing.env"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestUseDefaults(t *testing.T) {
+	testEnv(t, Map{"PORT": "8080"})
+	UseDefaults(Map{"PORT": "80", "NAME": "first"})
+	UseDefaults(Map{"NAME": "second", "LIMIT": "5"})
+	if got := String("PORT", "1"); got != "8080" {
+		t.Errorf("defaults override the environment: %q", got)
+	}
+	if got := String("NAME", "code"); got != "first" {
+		t.Errorf("defaults added first must take precedence: %q", got)
+	}
+	if got := Int("LIMIT", 1); got != 5 {
+		t.Errorf("defaults must override in-code defaults: %d", got)
+	}
+	if got := String("OTHER", "code"); got != "code" {
+		t.Errorf("unexpected value: %q", got)
+	}
+}
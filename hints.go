@@ -0,0 +1,121 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// formats describes accepted formats of each type, with examples. They are used in hints, see ParseError.
+var formats = map[string]string{
+	"bool":     `true or false (also 1, 0, t, f), for example "true"`,
+	"int":      `an integer, for example "42"`,
+	"float64":  `a number, for example "0.5"`,
+	"duration": `a number with a unit (ns, us, ms, s, m, h), for example "30s" or "1h30m"`,
+	"bytes":    `a size with an optional unit (k, M, G, T, or Ki, Mi, Gi, Ti, with an optional B), for example "512MiB"`,
+	"milli":    `a quantity with an optional suffix (m, k, M, G, or Ki, Mi, Gi), for example "500m" or "2"`,
+	"quantity": `a quantity with an optional suffix (k, M, G, or Ki, Mi, Gi), for example "256Mi" or "1G"`,
+}
+
+// valueFixes are corrections for common mistakes in values, applied in order on top of each other.
+var valueFixes = []func(typ, s string) string{
+	func(_, s string) string { return strings.TrimSpace(s) },
+	unquoteValue,
+	func(_, s string) string { return strings.Join(strings.Fields(s), "") },
+	removeThousands,
+	fixType,
+}
+
+// valueHint suggests a fix for a value that cannot be parsed as a given type, and describes the accepted format.
+// It returns the corrected value, if any, and an empty hint if the value is valid.
+func valueHint(typ, val string) (fix, hint string) {
+	p, ok := parsers[typ]
+	if !ok || val == "" {
+		return "", ""
+	}
+	if _, err := p(val); err == nil {
+		return "", ""
+	}
+	format, ok := formats[typ]
+	if !ok {
+		return "", ""
+	}
+	s := val
+	for _, f := range valueFixes {
+		s = f(typ, s)
+		if _, err := p(s); err == nil && s != "" {
+			return s, fmt.Sprintf("did you mean %q? (expected %s)", s, format)
+		}
+	}
+	return "", "expected " + format
+}
+
+// unquoteValue removes quotes around the value, which are commonly left by mistake in manifests and dotenv files.
+func unquoteValue(_, s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return strings.TrimSpace(s[1 : len(s)-1])
+	}
+	return s
+}
+
+var thousandsRe = regexp.MustCompile(`^[+-]?\d{1,3}([,_]\d{3})+(\.\d*)?`)
+
+// removeThousands removes thousands separators: "1,000" becomes "1000".
+func removeThousands(_, s string) string {
+	if m := thousandsRe.FindString(s); m != "" {
+		return strings.NewReplacer(",", "", "_", "").Replace(m) + s[len(m):]
+	}
+	return s
+}
+
+var durationUnits = map[string]string{
+	"sec": "s", "secs": "s", "second": "s", "seconds": "s",
+	"min": "m", "mins": "m", "minute": "m", "minutes": "m",
+	"hr": "h", "hrs": "h", "hour": "h", "hours": "h",
+	"msec": "ms", "millis": "ms", "millisecond": "ms", "milliseconds": "ms",
+}
+
+// fixType corrects type-specific mistakes.
+func fixType(typ, s string) string {
+	switch typ {
+	case "duration":
+		num := strings.TrimRightFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+		unit := strings.ToLower(s[len(num):])
+		if _, err := strconv.ParseFloat(num, 64); err != nil || strings.Contains(num, "e") {
+			return s
+		}
+		switch {
+		case unit == "":
+			return s + "s"
+		case unit == "d" || unit == "day" || unit == "days":
+			if n, err := strconv.Atoi(num); err == nil {
+				return strconv.Itoa(n*24) + "h"
+			}
+		case durationUnits[unit] != "":
+			return num + durationUnits[unit]
+		}
+	case "bool":
+		l := strings.ToLower(s)
+		switch {
+		case l == "yes" || l == "y" || l == "on" || l == "enabled" || strings.HasPrefix(l, "true"):
+			return "true"
+		case l == "no" || l == "n" || l == "off" || l == "disabled" || strings.HasPrefix(l, "false"):
+			return "false"
+		}
+	case "int":
+		if f, err := strconv.ParseFloat(s, 64); err == nil && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return strconv.FormatInt(int64(f), 10)
+		}
+	case "float64":
+		if strings.Count(s, ",") == 1 && !strings.Contains(s, ".") {
+			return strings.Replace(s, ",", ".", 1)
+		}
+	case "milli", "quantity":
+		if t := strings.TrimSuffix(s, "B"); t != s && !strings.HasSuffix(t, "B") {
+			return t
+		}
+	}
+	return s
+}
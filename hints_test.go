@@ -0,0 +1,62 @@
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValueHint(t *testing.T) {
+	for _, c := range []struct {
+		typ, val string
+		fix      string
+		hint     bool
+	}{
+		{typ: "duration", val: "30", fix: "30s", hint: true},
+		{typ: "duration", val: "5 minutes", fix: "5m", hint: true},
+		{typ: "duration", val: "2 days", fix: "48h", hint: true},
+		{typ: "duration", val: "'30s'", fix: "30s", hint: true},
+		{typ: "duration", val: "soon", hint: true},
+		{typ: "bytes", val: "10 MB", fix: "10MB", hint: true},
+		{typ: "bytes", val: `"512MiB"`, fix: "512MiB", hint: true},
+		{typ: "bool", val: "true1", fix: "true", hint: true},
+		{typ: "bool", val: "yes", fix: "true", hint: true},
+		{typ: "bool", val: "Off", fix: "false", hint: true},
+		{typ: "bool", val: "maybe", hint: true},
+		{typ: "int", val: "1,000", fix: "1000", hint: true},
+		{typ: "int", val: "1_000_000", fix: "1000000", hint: true},
+		{typ: "int", val: `"42"`, fix: "42", hint: true},
+		{typ: "int", val: " 42 ", fix: "42", hint: true},
+		{typ: "int", val: "2.0", fix: "2", hint: true},
+		{typ: "int", val: "2.5", hint: true},
+		{typ: "float64", val: "0,5", fix: "0.5", hint: true},
+		{typ: "float64", val: "1,000.5", fix: "1000.5", hint: true},
+		{typ: "quantity", val: "256MiB", fix: "256Mi", hint: true},
+		// valid values, empty values and types without formats have no hints
+		{typ: "int", val: "42"},
+		{typ: "duration", val: "30s"},
+		{typ: "int", val: ""},
+		{typ: "string", val: "'x'"},
+		{typ: "unknown", val: "x"},
+	} {
+		fix, hint := valueHint(c.typ, c.val)
+		if fix != c.fix || (hint != "") != c.hint {
+			t.Errorf("%s %q: unexpected fix %q, hint %q", c.typ, c.val, fix, hint)
+		}
+		if c.hint && !strings.Contains(hint, formats[c.typ]) {
+			t.Errorf("%s %q: hint does not describe the format: %q", c.typ, c.val, hint)
+		}
+	}
+}
+
+func TestParseErrorHint(t *testing.T) {
+	testEnv(t, Map{"TIMEOUT": "30"})
+	var perr *ParseError
+	_, err := Parse("TIMEOUT", "duration", "30")
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+	if perr.Fix != "30s" || !strings.Contains(err.Error(), `did you mean "30s"?`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+// Package toml implements a decoder for a commonly used subset of TOML.
+//
+// It supports tables, arrays of tables, dotted keys, basic and literal strings,
+// integers, floats, booleans, and arrays and inline tables on a single line.
+// Multi-line strings and date-time values are not supported.
+package toml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal decodes a TOML document. Tables are decoded as map[string]any and arrays as []any.
+func Unmarshal(data []byte) (map[string]any, error) {
+	root := make(map[string]any)
+	cur := root
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		ln := i + 1
+		p := &parser{s: line}
+		p.skipSpace()
+		if p.eol() {
+			continue
+		}
+		var err error
+		switch {
+		case strings.HasPrefix(p.s[p.i:], "[["):
+			p.i += 2
+			cur, err = p.table(root, true)
+		case p.s[p.i] == '[':
+			p.i++
+			cur, err = p.table(root, false)
+		default:
+			err = p.keyValue(cur)
+		}
+		if err == nil && !p.eol() {
+			err = fmt.Errorf("unexpected %q", p.s[p.i:])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("toml: line %d: %v", ln, err)
+		}
+	}
+	return root, nil
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+func (p *parser) skipSpace() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+// eol reports if the rest of the line is empty or a comment.
+func (p *parser) eol() bool {
+	p.skipSpace()
+	return p.i == len(p.s) || p.s[p.i] == '#'
+}
+
+func (p *parser) expect(c byte) error {
+	p.skipSpace()
+	if p.i == len(p.s) || p.s[p.i] != c {
+		return fmt.Errorf("expected '%c'", c)
+	}
+	p.i++
+	return nil
+}
+
+// key parses a possibly dotted key.
+func (p *parser) key() ([]string, error) {
+	var parts []string
+	for {
+		p.skipSpace()
+		if p.i == len(p.s) {
+			return nil, fmt.Errorf("expected a key")
+		}
+		var k string
+		switch p.s[p.i] {
+		case '"', '\'':
+			v, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			k = v
+		default:
+			j := p.i
+			for j < len(p.s) && (isBare(p.s[j])) {
+				j++
+			}
+			if j == p.i {
+				return nil, fmt.Errorf("expected a key")
+			}
+			k, p.i = p.s[p.i:j], j
+		}
+		parts = append(parts, k)
+		p.skipSpace()
+		if p.i < len(p.s) && p.s[p.i] == '.' {
+			p.i++
+			continue
+		}
+		return parts, nil
+	}
+}
+
+func isBare(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// descend returns a nested table for the key path, creating it if necessary.
+// For arrays of tables the last element is used.
+func descend(t map[string]any, path []string) (map[string]any, error) {
+	for _, k := range path {
+		switch v := t[k].(type) {
+		case nil:
+			m := make(map[string]any)
+			t[k] = m
+			t = m
+		case map[string]any:
+			t = v
+		case []any:
+			if len(v) == 0 {
+				return nil, fmt.Errorf("key %q is an empty array", k)
+			}
+			m, ok := v[len(v)-1].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("key %q is not a table", k)
+			}
+			t = m
+		default:
+			return nil, fmt.Errorf("key %q is not a table", k)
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) table(root map[string]any, array bool) (map[string]any, error) {
+	path, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+	if !array {
+		return descend(root, path)
+	}
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+	parent, err := descend(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	m := make(map[string]any)
+	switch v := parent[last].(type) {
+	case nil:
+		parent[last] = []any{m}
+	case []any:
+		parent[last] = append(v, m)
+	default:
+		return nil, fmt.Errorf("key %q is not an array of tables", last)
+	}
+	return m, nil
+}
+
+func (p *parser) keyValue(t map[string]any) error {
+	path, err := p.key()
+	if err != nil {
+		return err
+	}
+	if err := p.expect('='); err != nil {
+		return err
+	}
+	v, err := p.value()
+	if err != nil {
+		return err
+	}
+	t, err = descend(t, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	if _, ok := t[last]; ok {
+		return fmt.Errorf("duplicate key %q", last)
+	}
+	t[last] = v
+	return nil
+}
+
+func (p *parser) value() (any, error) {
+	p.skipSpace()
+	if p.i == len(p.s) {
+		return nil, fmt.Errorf("expected a value")
+	}
+	switch p.s[p.i] {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		p.i++
+		out := []any{}
+		for {
+			if p.skipSpace(); p.i < len(p.s) && p.s[p.i] == ']' {
+				p.i++
+				return out, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			if p.skipSpace(); p.i < len(p.s) && p.s[p.i] == ',' {
+				p.i++
+			} else if err := p.expect(']'); err != nil {
+				return nil, err
+			} else {
+				return out, nil
+			}
+		}
+	case '{':
+		p.i++
+		out := make(map[string]any)
+		for {
+			if p.skipSpace(); p.i < len(p.s) && p.s[p.i] == '}' {
+				p.i++
+				return out, nil
+			}
+			if err := p.keyValue(out); err != nil {
+				return nil, err
+			}
+			if p.skipSpace(); p.i < len(p.s) && p.s[p.i] == ',' {
+				p.i++
+			} else if err := p.expect('}'); err != nil {
+				return nil, err
+			} else {
+				return out, nil
+			}
+		}
+	}
+	j := p.i
+	for j < len(p.s) && !strings.ContainsRune(" \t,]}#", rune(p.s[j])) {
+		j++
+	}
+	s := p.s[p.i:j]
+	p.i = j
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	clean := strings.ReplaceAll(s, "_", "")
+	digits := strings.TrimLeft(clean, "+-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9' {
+		return nil, fmt.Errorf("leading zeros are not allowed in %q", s)
+	}
+	base := 10
+	if len(digits) > 1 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x':
+			base = 16
+		case 'o':
+			base = 8
+		case 'b':
+			base = 2
+		}
+	}
+	if base != 10 {
+		if len(digits) != len(clean) {
+			return nil, fmt.Errorf("signs are not allowed in %q", s)
+		}
+		if n, err := strconv.ParseInt(digits[2:], base, 64); err == nil {
+			return n, nil
+		}
+		return nil, fmt.Errorf("unsupported value %q", s)
+	}
+	if n, err := strconv.ParseInt(clean, 10, 64); err == nil {
+		return n, nil
+	}
+	if strings.ContainsAny(digits, "0123456789") && !strings.ContainsAny(clean, "xXpP") {
+		if f, err := strconv.ParseFloat(clean, 64); err == nil {
+			return f, nil
+		}
+	}
+	switch digits {
+	case "inf":
+		return strconv.ParseFloat(clean, 64)
+	case "nan":
+		return strconv.ParseFloat(digits, 64)
+	}
+	return nil, fmt.Errorf("unsupported value %q", s)
+}
+
+func (p *parser) str() (string, error) {
+	q := p.s[p.i]
+	if strings.HasPrefix(p.s[p.i:], strings.Repeat(string(q), 3)) {
+		return "", fmt.Errorf("multi-line strings are not supported")
+	}
+	j := p.i + 1
+	for ; j < len(p.s) && p.s[j] != q; j++ {
+		if q == '"' && p.s[j] == '\\' {
+			j++
+		}
+	}
+	if j >= len(p.s) {
+		return "", fmt.Errorf("unterminated string")
+	}
+	s := p.s[p.i : j+1]
+	p.i = j + 1
+	if q == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
@@ -0,0 +1,146 @@
+package toml
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var unmarshalCases = []struct {
+	name string
+	in   string
+	exp  map[string]any
+}{
+	{name: "empty", in: "", exp: map[string]any{}},
+	{name: "comments", in: "# comment\n\n  # indented\n", exp: map[string]any{}},
+	{
+		name: "key values",
+		in:   "a = \"x\"\nb = 1\nc = true\nd = false # comment\n",
+		exp:  map[string]any{"a": "x", "b": int64(1), "c": true, "d": false},
+	},
+	{
+		name: "strings",
+		in:   "a = \"x\\ty\"\nb = 'c:\\path'\nc = \"# not a comment\"\nd = \"\"\n",
+		exp:  map[string]any{"a": "x\ty", "b": `c:\path`, "c": "# not a comment", "d": ""},
+	},
+	{
+		name: "numbers",
+		in:   "a = 10\nb = -3\nc = +4\nd = 1_000\ne = 0x1f\nf = 0o17\ng = 0b101\nh = 1.5\ni = -1e3\nj = 0\nk = 0.5\n",
+		exp: map[string]any{
+			"a": int64(10), "b": int64(-3), "c": int64(4), "d": int64(1000), "e": int64(31),
+			"f": int64(15), "g": int64(5), "h": 1.5, "i": -1000.0, "j": int64(0), "k": 0.5,
+		},
+	},
+	{
+		name: "dotted and quoted keys",
+		in:   "a.b = 1\na.c = 2\n\"d.e\" = 3\n'f' = 4\n",
+		exp:  map[string]any{"a": map[string]any{"b": int64(1), "c": int64(2)}, "d.e": int64(3), "f": int64(4)},
+	},
+	{
+		name: "tables",
+		in:   "top = 1\n[a]\nx = 1\n[a.b]\ny = 2\n[c . d]\nz = 3\n",
+		exp: map[string]any{
+			"top": int64(1),
+			"a":   map[string]any{"x": int64(1), "b": map[string]any{"y": int64(2)}},
+			"c":   map[string]any{"d": map[string]any{"z": int64(3)}},
+		},
+	},
+	{
+		name: "arrays of tables",
+		in:   "[[vars]]\nname = \"A\"\n[[vars]]\nname = \"B\"\n[vars.sub]\nx = 1\n",
+		exp: map[string]any{"vars": []any{
+			map[string]any{"name": "A"},
+			map[string]any{"name": "B", "sub": map[string]any{"x": int64(1)}},
+		}},
+	},
+	{
+		name: "arrays",
+		in:   "a = [1, \"x\", [2]]\nb = []\nc = [ 1 , 2 , ]\n",
+		exp:  map[string]any{"a": []any{int64(1), "x", []any{int64(2)}}, "b": []any{}, "c": []any{int64(1), int64(2)}},
+	},
+	{
+		name: "inline tables",
+		in:   "a = {x = 1, y.z = \"s\"}\nb = {}\n",
+		exp:  map[string]any{"a": map[string]any{"x": int64(1), "y": map[string]any{"z": "s"}}, "b": map[string]any{}},
+	},
+	{
+		name: "crlf",
+		in:   "a = 1\r\nb = 2\r\n",
+		exp:  map[string]any{"a": int64(1), "b": int64(2)},
+	},
+}
+
+func TestUnmarshal(t *testing.T) {
+	for _, c := range unmarshalCases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Unmarshal([]byte(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.exp) {
+				t.Fatalf("unexpected result:\n%#v\nvs\n%#v", got, c.exp)
+			}
+		})
+	}
+}
+
+func TestUnmarshalSpecialFloats(t *testing.T) {
+	got, err := Unmarshal([]byte("a = inf\nb = -inf\nc = nan\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !math.IsInf(got["a"].(float64), 1) || !math.IsInf(got["b"].(float64), -1) || !math.IsNaN(got["c"].(float64)) {
+		t.Fatalf("unexpected result: %v", got)
+	}
+}
+
+var unmarshalErrors = []struct {
+	name string
+	in   string
+	err  string
+}{
+	{name: "missing value", in: "a =\n", err: "line 1: expected a value"},
+	{name: "missing equals", in: "a 1\n", err: "expected '='"},
+	{name: "missing key", in: "= 1\n", err: "expected a key"},
+	{name: "duplicate key", in: "a = 1\na = 2\n", err: "line 2: duplicate key \"a\""},
+	{name: "trailing content", in: "a = 1 2\n", err: "unexpected \"2\""},
+	{name: "unterminated string", in: "a = \"x\n", err: "unterminated string"},
+	{name: "multi-line string", in: "a = \"\"\"x\"\"\"\n", err: "multi-line strings are not supported"},
+	{name: "unsupported value", in: "a = 2024-01-01T00:00:00Z\n", err: "unsupported value"},
+	{name: "leading zeros", in: "a = 010\n", err: "leading zeros are not allowed"},
+	{name: "signed hex", in: "a = -0x10\n", err: "signs are not allowed"},
+	{name: "unterminated table", in: "[a\n", err: "expected ']'"},
+	{name: "unterminated array of tables", in: "[[a]\n", err: "expected ']'"},
+	{name: "unterminated array", in: "a = [1, 2\n", err: "expected ']'"},
+	{name: "unterminated inline table", in: "a = {x = 1\n", err: "expected '}'"},
+	{name: "value is not a table", in: "a = 1\n[a.b]\n", err: "key \"a\" is not a table"},
+	{name: "empty array as a table", in: "a = []\n[a.b]\n", err: "key \"a\" is an empty array"},
+	{name: "array of values as a table", in: "a = [1]\n[a.b]\n", err: "key \"a\" is not a table"},
+	{name: "table as an array of tables", in: "[a]\n[[a]]\n", err: "key \"a\" is not an array of tables"},
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, c := range unmarshalErrors {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(c.in))
+			if err == nil {
+				t.Fatal("expected an error")
+			} else if !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error containing %q, got %q", c.err, err)
+			}
+		})
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, c := range unmarshalCases {
+		f.Add(c.in)
+	}
+	for _, c := range unmarshalErrors {
+		f.Add(c.in)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		Unmarshal([]byte(s))
+	})
+}
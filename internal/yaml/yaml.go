@@ -0,0 +1,470 @@
+// Package yaml implements a decoder for a commonly used subset of YAML.
+//
+// It supports block mappings and sequences, flow collections on a single line,
+// plain, single- and double-quoted scalars, literal and folded block scalars and multiple documents.
+// Anchors, aliases and tags are not supported.
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal decodes all documents from data. Mappings are decoded as map[string]any and sequences as []any.
+func Unmarshal(data []byte) ([]any, error) {
+	var (
+		docs []any
+		cur  []string
+	)
+	flush := func(first int) error {
+		p := &parser{first: first}
+		for _, s := range cur {
+			p.lines = append(p.lines, newLine(s))
+		}
+		cur = cur[:0]
+		p.skipBlank()
+		if p.pos == len(p.lines) {
+			return nil
+		}
+		v, err := p.parseNode(p.lines[p.pos].indent)
+		if err != nil {
+			return err
+		}
+		if p.skipBlank(); p.pos < len(p.lines) {
+			return p.errorf("unexpected content")
+		}
+		docs = append(docs, v)
+		return nil
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	first := 1
+	for i, s := range lines {
+		if t := strings.TrimRight(s, " \t"); t == "---" || t == "..." || strings.HasPrefix(t, "--- ") {
+			if err := flush(first); err != nil {
+				return nil, err
+			}
+			first = i + 2
+			continue
+		}
+		cur = append(cur, s)
+	}
+	if err := flush(first); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+type line struct {
+	raw    string
+	indent int
+	text   string // without indentation and comments
+}
+
+func newLine(s string) line {
+	t := strings.TrimLeft(s, " ")
+	return line{raw: s, indent: len(s) - len(t), text: strings.TrimSpace(stripComment(t))}
+}
+
+// stripComment removes a trailing comment from the line, taking quotes into account.
+func stripComment(s string) string {
+	var q byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case q != 0:
+			if c == '\\' && q == '"' {
+				i++
+			} else if c == q {
+				q = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t:-[{,", rune(s[i-1])) {
+				q = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type parser struct {
+	lines []line
+	pos   int
+	first int // line number of the first line
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("yaml: line %d: %s", p.first+p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+}
+
+func isSeqItem(s string) bool {
+	return s == "-" || strings.HasPrefix(s, "- ")
+}
+
+// mapColon returns the index of the colon separating a mapping key from the value, or -1.
+func mapColon(s string) int {
+	if s == "" || s[0] == '[' || s[0] == '{' {
+		return -1
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 {
+			return -1
+		}
+		if rest := s[end+2:]; strings.HasPrefix(rest, ":") && (len(rest) == 1 || rest[1] == ' ') {
+			return end + 2
+		}
+		return -1
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseNode parses a node starting at the current line, which must have the given indentation.
+func (p *parser) parseNode(indent int) (any, error) {
+	l := p.lines[p.pos]
+	switch {
+	case isSeqItem(l.text):
+		return p.parseSeq(indent)
+	case mapColon(l.text) >= 0:
+		return p.parseMap(indent)
+	}
+	p.pos++
+	return parseScalar(l.text)
+}
+
+func (p *parser) parseSeq(indent int) ([]any, error) {
+	out := []any{}
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		l := p.lines[p.pos]
+		if l.indent < indent || (l.indent == indent && !isSeqItem(l.text)) {
+			// a sequence may have the same indentation as the parent mapping
+			break
+		} else if l.indent > indent {
+			return nil, p.errorf("unexpected indentation in a sequence")
+		}
+		item := strings.TrimLeft(l.text[1:], " ")
+		if item == "" {
+			p.pos++
+			v, err := p.parseNested(indent)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			continue
+		}
+		// treat the item content as a new line with a deeper indentation
+		off := l.indent + len(l.text) - len(item)
+		p.lines[p.pos] = line{raw: strings.Repeat(" ", off) + item, indent: off, text: item}
+		v, err := p.parseNode(off)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// parseNested parses a value that follows a line with an empty value at the given indentation.
+func (p *parser) parseNested(indent int) (any, error) {
+	p.skipBlank()
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+	l := p.lines[p.pos]
+	if l.indent > indent {
+		return p.parseNode(l.indent)
+	}
+	return nil, nil
+}
+
+func (p *parser) parseMap(indent int) (map[string]any, error) {
+	out := make(map[string]any)
+	for p.skipBlank(); p.pos < len(p.lines); p.skipBlank() {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		} else if l.indent > indent {
+			return nil, p.errorf("unexpected indentation in a mapping")
+		}
+		i := mapColon(l.text)
+		if i < 0 {
+			return nil, p.errorf("expected a mapping key")
+		}
+		key, err := parseScalar(strings.TrimSpace(l.text[:i]))
+		if err != nil {
+			return nil, err
+		}
+		rest := strings.TrimSpace(l.text[i+1:])
+		p.pos++
+		var v any
+		switch {
+		case rest == "":
+			p.skipBlank()
+			if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSeqItem(p.lines[p.pos].text) {
+				v, err = p.parseSeq(indent)
+			} else {
+				v, err = p.parseNested(indent)
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			v = p.parseBlockScalar(indent, rest)
+		default:
+			v, err = parseScalar(rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[fmt.Sprint(key)] = v
+	}
+	return out, nil
+}
+
+// parseBlockScalar reads a literal (|) or folded (>) block scalar nested deeper than indent.
+func (p *parser) parseBlockScalar(indent int, header string) string {
+	var lines []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		l := p.lines[p.pos]
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if l.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = l.indent
+		}
+		if l.indent < blockIndent {
+			break
+		}
+		lines = append(lines, l.raw[blockIndent:])
+	}
+	// trailing empty lines are not part of the block
+	n := len(lines)
+	for n > 0 && lines[n-1] == "" {
+		n--
+	}
+	p.pos -= len(lines) - n
+	lines = lines[:n]
+	var s string
+	if header[0] == '|' {
+		s = strings.Join(lines, "\n")
+	} else {
+		var sb strings.Builder
+		for i, l := range lines {
+			// a line break is folded into a space, unless it is next to an empty line
+			switch {
+			case i == 0:
+			case l == "":
+				sb.WriteByte('\n')
+			case lines[i-1] != "":
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(l)
+		}
+		s = sb.String()
+	}
+	if !strings.HasSuffix(header, "-") && s != "" {
+		s += "\n"
+	}
+	return s
+}
+
+// parseScalar parses a single-line scalar or a flow collection.
+func parseScalar(s string) (any, error) {
+	if s != "" && (s[0] == '[' || s[0] == '{') {
+		f := &flow{s: s}
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		if f.skipSpace(); f.i != len(s) {
+			return nil, fmt.Errorf("yaml: unexpected %q after a flow collection", s[f.i:])
+		}
+		return v, nil
+	}
+	switch {
+	case s == "":
+		return nil, nil
+	case s[0] == '"':
+		return unquoteDouble(s)
+	case s[0] == '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' {
+			return nil, fmt.Errorf("yaml: unterminated string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return plain(s), nil
+}
+
+func unquoteDouble(s string) (string, error) {
+	if len(s) < 2 || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("yaml: unterminated string %s", s)
+	}
+	if v, err := strconv.Unquote(s); err == nil {
+		return v, nil
+	}
+	// YAML allows escapes that Go does not, keep them as is
+	return strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`), nil
+}
+
+var floatRe = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+
+// plain resolves the type of a plain scalar, following the YAML 1.2 core schema.
+// Integers are decimal unless prefixed with "0x" or "0o". Numbers with leading zeros ("01234")
+// are kept as strings, since they are usually codes rather than numbers.
+func plain(s string) any {
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+	digits := strings.TrimLeft(s, "+-")
+	switch {
+	case strings.HasPrefix(digits, "0x"):
+		if n, err := strconv.ParseInt(s[:len(s)-len(digits)]+digits[2:], 16, 64); err == nil {
+			return n
+		}
+		return s
+	case strings.HasPrefix(digits, "0o"):
+		if n, err := strconv.ParseInt(s[:len(s)-len(digits)]+digits[2:], 8, 64); err == nil {
+			return n
+		}
+		return s
+	case len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9':
+		return s
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	if floatRe.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
+
+type flow struct {
+	s string
+	i int
+}
+
+func (f *flow) skipSpace() {
+	for f.i < len(f.s) && (f.s[f.i] == ' ' || f.s[f.i] == '\t') {
+		f.i++
+	}
+}
+
+func (f *flow) value() (any, error) {
+	f.skipSpace()
+	if f.i == len(f.s) {
+		return nil, fmt.Errorf("yaml: unexpected end of flow collection %s", f.s)
+	}
+	switch f.s[f.i] {
+	case '[':
+		f.i++
+		out := []any{}
+		for {
+			if f.skipSpace(); f.i < len(f.s) && f.s[f.i] == ']' {
+				f.i++
+				return out, nil
+			}
+			v, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			if err := f.sep(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.i++
+		out := make(map[string]any)
+		for {
+			if f.skipSpace(); f.i < len(f.s) && f.s[f.i] == '}' {
+				f.i++
+				return out, nil
+			}
+			k, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			if f.skipSpace(); f.i == len(f.s) || f.s[f.i] != ':' {
+				return nil, fmt.Errorf("yaml: expected ':' in flow mapping %s", f.s)
+			}
+			f.i++
+			v, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			out[fmt.Sprint(k)] = v
+			if err := f.sep('}'); err != nil {
+				return nil, err
+			}
+		}
+	case '"', '\'':
+		q := f.s[f.i]
+		j := f.i + 1
+		for ; j < len(f.s); j++ {
+			if f.s[j] == '\\' && q == '"' {
+				j++
+			} else if f.s[j] == q {
+				if q == '\'' && j+1 < len(f.s) && f.s[j+1] == '\'' {
+					j++ // escaped quote
+					continue
+				}
+				break
+			}
+		}
+		if j >= len(f.s) {
+			return nil, fmt.Errorf("yaml: unterminated string in %s", f.s)
+		}
+		s := f.s[f.i : j+1]
+		f.i = j + 1
+		return parseScalar(s)
+	}
+	j := f.i
+	for j < len(f.s) && !strings.ContainsRune(",]}", rune(f.s[j])) && !(f.s[j] == ':' && (j+1 == len(f.s) || f.s[j+1] == ' ')) {
+		j++
+	}
+	s := strings.TrimSpace(f.s[f.i:j])
+	f.i = j
+	return plain(s), nil
+}
+
+func (f *flow) sep(end byte) error {
+	f.skipSpace()
+	if f.i < len(f.s) && f.s[f.i] == ',' {
+		f.i++
+		return nil
+	}
+	if f.i < len(f.s) && f.s[f.i] == end {
+		return nil
+	}
+	return fmt.Errorf("yaml: expected ',' or '%c' in %s", end, f.s)
+}
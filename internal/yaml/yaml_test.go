@@ -0,0 +1,185 @@
+package yaml
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var unmarshalCases = []struct {
+	name string
+	in   string
+	exp  []any
+}{
+	{name: "empty", in: "", exp: nil},
+	{name: "comments only", in: "# comment\n\n  # indented\n", exp: nil},
+	{name: "plain scalar", in: "hello world", exp: []any{"hello world"}},
+	{
+		name: "mapping",
+		in:   "a: 1\nb: text\nc: true\nd: ~\ne:\n",
+		exp:  []any{map[string]any{"a": int64(1), "b": "text", "c": true, "d": nil, "e": nil}},
+	},
+	{
+		name: "nested mapping",
+		in:   "a:\n  b:\n    c: x\n  d: y\ne: z\n",
+		exp: []any{map[string]any{
+			"a": map[string]any{"b": map[string]any{"c": "x"}, "d": "y"},
+			"e": "z",
+		}},
+	},
+	{
+		name: "sequence",
+		in:   "- a\n- 2\n-\n  - nested\n",
+		exp:  []any{[]any{"a", int64(2), []any{"nested"}}},
+	},
+	{
+		name: "sequence of mappings",
+		in:   "- name: a\n  value: 1\n- name: b\n",
+		exp: []any{[]any{
+			map[string]any{"name": "a", "value": int64(1)},
+			map[string]any{"name": "b"},
+		}},
+	},
+	{
+		name: "sequence at mapping indent",
+		in:   "list:\n- a\n- b\nnext: c\n",
+		exp:  []any{map[string]any{"list": []any{"a", "b"}, "next": "c"}},
+	},
+	{
+		name: "indented sequence",
+		in:   "list:\n  - a\n  - b\n",
+		exp:  []any{map[string]any{"list": []any{"a", "b"}}},
+	},
+	{
+		name: "quoted scalars",
+		in:   "a: \"x\\ty\"\nb: 'it''s'\nc: \"# not a comment\"\n\"d e\": 1\n'f': 2\n",
+		exp:  []any{map[string]any{"a": "x\ty", "b": "it's", "c": "# not a comment", "d e": int64(1), "f": int64(2)}},
+	},
+	{
+		name: "comments",
+		in:   "a: b # comment\nc: d#e\n# full line\n",
+		exp:  []any{map[string]any{"a": "b", "c": "d#e"}},
+	},
+	{
+		name: "literal block",
+		in:   "a: |\n  line 1\n\n  line 2\nb: |-\n  x\n  y\n",
+		exp:  []any{map[string]any{"a": "line 1\n\nline 2\n", "b": "x\ny"}},
+	},
+	{
+		name: "folded block",
+		in:   "a: >\n  one\n  two\n\n  three\n",
+		exp:  []any{map[string]any{"a": "one two\nthree\n"}},
+	},
+	{
+		name: "flow collections",
+		in:   "a: [1, x, 'y z', [2], 'it''s']\nb: {k: v, n: 1}\nc: []\nd: {}\n",
+		exp: []any{map[string]any{
+			"a": []any{int64(1), "x", "y z", []any{int64(2)}, "it's"},
+			"b": map[string]any{"k": "v", "n": int64(1)},
+			"c": []any{},
+			"d": map[string]any{},
+		}},
+	},
+	{
+		name: "numbers",
+		in:   "a: 10\nb: -3\nc: 0x1f\nd: 0o17\ne: 1.5\nf: 1e3\ng: 010\nh: 01234\ni: 1_000\nj: 0\nk: +2\n",
+		exp: []any{map[string]any{
+			"a": int64(10), "b": int64(-3), "c": int64(31), "d": int64(15), "e": 1.5, "f": 1000.0,
+			"g": "010", "h": "01234", "i": "1_000", "j": int64(0), "k": int64(2),
+		}},
+	},
+	{
+		name: "booleans and nulls",
+		in:   "- true\n- False\n- TRUE\n- null\n- ~\n- yes\n",
+		exp:  []any{[]any{true, false, true, nil, nil, "yes"}},
+	},
+	{
+		name: "multiple documents",
+		in:   "a: 1\n---\nb: 2\n...\n--- \nc: 3\n",
+		exp:  []any{map[string]any{"a": int64(1)}, map[string]any{"b": int64(2)}, map[string]any{"c": int64(3)}},
+	},
+	{
+		name: "crlf",
+		in:   "a: 1\r\nb: 2\r\n",
+		exp:  []any{map[string]any{"a": int64(1), "b": int64(2)}},
+	},
+	{
+		name: "colon in value",
+		in:   "url: http://example.com:8080/x\n",
+		exp:  []any{map[string]any{"url": "http://example.com:8080/x"}},
+	},
+}
+
+func TestUnmarshal(t *testing.T) {
+	for _, c := range unmarshalCases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Unmarshal([]byte(c.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.exp) {
+				t.Fatalf("unexpected result:\n%#v\nvs\n%#v", got, c.exp)
+			}
+		})
+	}
+}
+
+func TestUnmarshalSpecialFloats(t *testing.T) {
+	got, err := Unmarshal([]byte("- .inf\n- -.inf\n- .nan\n- Infinity\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := got[0].([]any)
+	if !math.IsInf(list[0].(float64), 1) || !math.IsInf(list[1].(float64), -1) || !math.IsNaN(list[2].(float64)) {
+		t.Fatalf("unexpected result: %v", list)
+	}
+	if list[3] != "Infinity" {
+		t.Fatalf("expected a string, got %#v", list[3])
+	}
+}
+
+var unmarshalErrors = []struct {
+	name string
+	in   string
+	err  string
+}{
+	{name: "mapping indentation", in: "a: 1\n  b: 2\n", err: "line 2: unexpected indentation in a mapping"},
+	{name: "sequence indentation", in: "- a\n  - b\n", err: "line 2: unexpected indentation in a sequence"},
+	{name: "missing key", in: "a: 1\nb\n", err: "line 2: expected a mapping key"},
+	{name: "trailing content", in: "a\nb: 1\n", err: "line 2: unexpected content"},
+	{name: "error in second document", in: "a: 1\n---\nb: 1\n  c: 2\n", err: "line 4:"},
+	{name: "unterminated double quote", in: "a: \"x\n", err: "unterminated string"},
+	{name: "unterminated single quote", in: "a: 'x\n", err: "unterminated string"},
+	{name: "unterminated flow sequence", in: "a: [1, 2\n", err: "expected ',' or ']'"},
+	{name: "unterminated flow mapping", in: "a: {k: v\n", err: "expected ',' or '}'"},
+	{name: "flow mapping without colon", in: "a: {k}\n", err: "expected ':'"},
+	{name: "empty flow value", in: "a: [1,\n", err: "unexpected end of flow collection"},
+	{name: "content after flow", in: "a: [1] x\n", err: "after a flow collection"},
+	{name: "unterminated flow string", in: "a: ['x]\n", err: "unterminated string"},
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, c := range unmarshalErrors {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(c.in))
+			if err == nil {
+				t.Fatal("expected an error")
+			} else if !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error containing %q, got %q", c.err, err)
+			}
+		})
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, c := range unmarshalCases {
+		f.Add(c.in)
+	}
+	for _, c := range unmarshalErrors {
+		f.Add(c.in)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		Unmarshal([]byte(s))
+	})
+}
@@ -0,0 +1,123 @@
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KeyNormalizer converts variable names to a canonical form used to match them.
+// If set, a variable that is not found by the exact name is matched by its normalized name.
+// An exact match in any source (see Use) takes precedence over normalized matches in all sources.
+//
+// It is nil by default (only exact matches) and should be set before reading any variables:
+//
+//	env.KeyNormalizer = env.NormalizeFold // "app.http.port" and "APP_HTTP_PORT" will match
+var KeyNormalizer func(key string) string
+
+// NormalizeSeparators replaces dots and dashes with underscores: "app.http-port" becomes "app_http_port".
+func NormalizeSeparators(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// NormalizeFold is like NormalizeSeparators, but also converts the name to upper case to match it case-insensitively.
+func NormalizeFold(key string) string {
+	return strings.ToUpper(NormalizeSeparators(key))
+}
+
+// AmbiguousKeyError is passed to Log when several variables in the same source match a key after normalization.
+// The first of them in sorted order is used. Each ambiguity is logged once, but is reported by EffectiveVars
+// as long as it exists.
+type AmbiguousKeyError struct {
+	Key  string   // requested variable name
+	Keys []string // names of all matching variables, sorted
+}
+
+func (e *AmbiguousKeyError) Error() string {
+	return fmt.Sprintf("ambiguous variable name: %s all match %s", strings.Join(e.Keys, ", "), e.Key)
+}
+
+var (
+	ambiguousMu sync.Mutex
+	ambiguous   = make(map[string]bool) // ambiguities that were logged, see AmbiguousKeyError
+)
+
+// reportAmbiguous records the ambiguity for the key and logs it, if it was not logged before.
+func reportAmbiguous(err *AmbiguousKeyError) {
+	id := err.Key + "=" + strings.Join(err.Keys, ",")
+	ambiguousMu.Lock()
+	logged := ambiguous[id]
+	ambiguous[id] = true
+	ambiguousMu.Unlock()
+	if logged {
+		recordError(err.Key, err)
+	} else {
+		logError(err.Key, err)
+	}
+}
+
+// lookupNormalized finds a non-empty variable in the source with a name that matches the key after normalization.
+func lookupNormalized(src Source, key string) (val, raw, from string, ok bool) {
+	norm := KeyNormalizer
+	if norm == nil {
+		return "", "", "", false
+	}
+	nk := norm(key)
+	var found []string
+	for _, k := range src.Keys() {
+		if k == key || norm(k) != nk {
+			continue
+		}
+		if s, _, ok := src.Lookup(k); ok && s != "" {
+			found = append(found, k)
+		}
+	}
+	if len(found) == 0 {
+		return "", "", "", false
+	}
+	sort.Strings(found)
+	if len(found) > 1 {
+		reportAmbiguous(&AmbiguousKeyError{Key: key, Keys: found})
+	}
+	val, from, _ = src.Lookup(found[0])
+	return val, found[0], from, true
+}
+
+// Ambiguous returns groups of variables that match the same name after normalization with KeyNormalizer.
+// Variables are checked in the process environment and in all sources, each source separately.
+func Ambiguous() [][]string {
+	norm := KeyNormalizer
+	if norm == nil {
+		return nil
+	}
+	srcMu.RLock()
+	var list []Source
+	if !noOSEnv {
+		list = append(list, osEnv{})
+	}
+	list = append(list, sources...)
+	list = append(list, defaults...)
+	srcMu.RUnlock()
+	var out [][]string
+	for _, src := range list {
+		groups := make(map[string][]string)
+		for _, k := range src.Keys() {
+			nk := norm(k)
+			groups[nk] = append(groups[nk], k)
+		}
+		for _, g := range groups {
+			if len(g) > 1 {
+				sort.Strings(g)
+				out = append(out, g)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][0] < out[j][0] })
+	return out
+}
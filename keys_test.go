@@ -0,0 +1,51 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+func withNormalizer(t *testing.T, norm func(string) string) {
+	old := KeyNormalizer
+	KeyNormalizer = norm
+	t.Cleanup(func() { KeyNormalizer = old })
+}
+
+func TestLookupNormalized(t *testing.T) {
+	withNormalizer(t, NormalizeFold)
+	testEnv(t, Map{"app.port": "1", "app.name": "a"})
+	Use(Map{"APP_PORT": "2"})
+	for _, c := range []struct {
+		key, exp, from string
+	}{
+		// an exact match in a later source wins over a normalized match in an earlier one
+		{"APP_PORT", "2", "APP_PORT"},
+		{"app.port", "1", "app.port"},
+		{"APP_NAME", "a", "app.name"},
+		{"app-name", "a", "app.name"},
+	} {
+		v, ok := Lookup(c.key)
+		if !ok || v.Value != c.exp || v.From != c.from {
+			t.Errorf("%s: unexpected value: %+v", c.key, v)
+		}
+	}
+}
+
+func TestLookupAmbiguous(t *testing.T) {
+	withNormalizer(t, NormalizeFold)
+	testEnv(t, Map{"app.port": "1", "app-port": "2"})
+	var logged []error
+	Log = func(key string, err error) { logged = append(logged, err) }
+	for i := 0; i < 3; i++ {
+		if v, ok := Lookup("APP_PORT"); !ok || v.Value != "2" {
+			t.Fatalf("unexpected value: %+v", v)
+		}
+	}
+	var aerr *AmbiguousKeyError
+	if len(logged) != 1 || !errors.As(logged[0], &aerr) {
+		t.Fatalf("expected the ambiguity to be logged once, got %v", logged)
+	}
+	if got := Ambiguous(); len(got) != 1 || len(got[0]) != 2 {
+		t.Fatalf("unexpected ambiguous keys: %v", got)
+	}
+}
@@ -0,0 +1,343 @@
+package env
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/env/internal/yaml"
+)
+
+// workloadKinds maps Kubernetes workload kinds to the path of a pod spec in them.
+var workloadKinds = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+type k8sWorkload struct {
+	kind, name string
+	pod        map[string]any
+}
+
+// ReadKubernetes reads the environment of a container from a file with Kubernetes manifests, for example
+// to check a Deployment with typed getters or Var.Check before it is applied.
+//
+// The workload is selected by name, optionally prefixed with its kind ("web" or "Deployment/web"),
+// and the container by its name. Empty names select the only workload or container in the file.
+// Pods, Deployments, StatefulSets, DaemonSets, ReplicaSets, Jobs and CronJobs are supported,
+// as well as lists of them.
+//
+// Variables are resolved like Kubernetes does: envFrom entries first, then env entries,
+// with $(VAR) references expanded. References to ConfigMaps and Secrets are resolved if they are defined
+// in the same file, and skipped otherwise. Field and resource references are skipped as well.
+func ReadKubernetes(file, workload, container string) (*Dotenv, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := yaml.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	var (
+		objs       []map[string]any
+		configMaps = make(map[string]map[string]string)
+		secrets    = make(map[string]map[string]string)
+		workloads  []k8sWorkload
+	)
+	for _, d := range docs {
+		m, _ := d.(map[string]any)
+		if items, ok := m["items"].([]any); ok && strings.HasSuffix(yamlString(m["kind"]), "List") {
+			for _, it := range items {
+				if m, ok := it.(map[string]any); ok {
+					objs = append(objs, m)
+				}
+			}
+		} else if m != nil {
+			objs = append(objs, m)
+		}
+	}
+	for _, m := range objs {
+		kind, name := yamlString(m["kind"]), yamlString(yamlPath(m, "metadata", "name"))
+		switch kind {
+		case "ConfigMap":
+			configMaps[name] = yamlStrings(m["data"])
+		case "Secret":
+			vals := make(map[string]string)
+			for k, v := range yamlStrings(m["data"]) {
+				b, err := base64.StdEncoding.DecodeString(v)
+				if err != nil {
+					return nil, fmt.Errorf("%s: secret %s: key %s: %w", file, name, k, err)
+				}
+				vals[k] = string(b)
+			}
+			for k, v := range yamlStrings(m["stringData"]) {
+				vals[k] = v
+			}
+			secrets[name] = vals
+		default:
+			if p, ok := workloadKinds[kind]; ok {
+				pod, _ := yamlPath(m, p...).(map[string]any)
+				workloads = append(workloads, k8sWorkload{kind: kind, name: name, pod: pod})
+			}
+		}
+	}
+	w, err := selectWorkload(workloads, workload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	c, err := selectContainer(w, container)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s/%s: %w", file, w.kind, w.name, err)
+	}
+	d := &Dotenv{vals: make(map[string]dotenvValue)}
+	set := func(key, val string) {
+		d.vals[key] = dotenvValue{value: val, file: file}
+	}
+	list, _ := c["envFrom"].([]any)
+	for _, e := range list {
+		e, _ := e.(map[string]any)
+		prefix := yamlString(e["prefix"])
+		var vals map[string]string
+		if ref, ok := e["configMapRef"].(map[string]any); ok {
+			vals = configMaps[yamlString(ref["name"])]
+		} else if ref, ok := e["secretRef"].(map[string]any); ok {
+			vals = secrets[yamlString(ref["name"])]
+		}
+		for k, v := range vals {
+			set(prefix+k, v)
+		}
+	}
+	list, _ = c["env"].([]any)
+	for _, e := range list {
+		e, _ := e.(map[string]any)
+		name := yamlString(e["name"])
+		if name == "" {
+			continue
+		}
+		if v, ok := e["value"]; ok {
+			set(name, expandKubernetes(yamlString(v), d))
+			continue
+		}
+		from, _ := e["valueFrom"].(map[string]any)
+		var vals map[string]string
+		ref, ok := from["configMapKeyRef"].(map[string]any)
+		if ok {
+			vals = configMaps[yamlString(ref["name"])]
+		} else if ref, ok = from["secretKeyRef"].(map[string]any); ok {
+			vals = secrets[yamlString(ref["name"])]
+		}
+		if v, ok := vals[yamlString(ref["key"])]; ok {
+			set(name, v)
+		}
+	}
+	return d, nil
+}
+
+func selectWorkload(list []k8sWorkload, sel string) (*k8sWorkload, error) {
+	kind, name, ok := strings.Cut(sel, "/")
+	if !ok {
+		kind, name = "", kind
+	}
+	var found []*k8sWorkload
+	for i, w := range list {
+		if (kind == "" || w.kind == kind) && (name == "" || w.name == name) {
+			found = append(found, &list[i])
+		}
+	}
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) == 0 && name == "":
+		return nil, fmt.Errorf("no workloads found")
+	case len(found) == 0:
+		return nil, fmt.Errorf("workload %q not found", sel)
+	}
+	var names []string
+	for _, w := range found {
+		names = append(names, w.kind+"/"+w.name)
+	}
+	return nil, fmt.Errorf("multiple workloads found, select one of: %s", strings.Join(names, ", "))
+}
+
+func selectContainer(w *k8sWorkload, name string) (map[string]any, error) {
+	list, _ := w.pod["containers"].([]any)
+	var names []string
+	for _, c := range list {
+		c, _ := c.(map[string]any)
+		cname := yamlString(c["name"])
+		if cname == name || (name == "" && len(list) == 1) {
+			return c, nil
+		}
+		names = append(names, cname)
+	}
+	switch {
+	case len(names) == 0:
+		return nil, fmt.Errorf("no containers found")
+	case name != "":
+		return nil, fmt.Errorf("container %q not found", name)
+	}
+	return nil, fmt.Errorf("multiple containers found, select one of: %s", strings.Join(names, ", "))
+}
+
+// expandKubernetes expands $(VAR) references to previously defined variables, as Kubernetes does.
+// References to undefined variables are left as is, and "$$" is replaced with "$".
+func expandKubernetes(s string, d *Dotenv) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+			continue
+		case '(':
+			if end := strings.IndexByte(s[i:], ')'); end > 0 {
+				name := s[i+2 : i+end]
+				if v, ok := d.vals[name]; ok {
+					sb.WriteString(v.value)
+					i += end
+					continue
+				}
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// ReadCompose reads the environment of a service from a Docker Compose file, for example
+// to check it with typed getters or Var.Check before it is deployed.
+//
+// An empty service name selects the only service in the file. Files from env_file entries are read first
+// (as dotenv files, relative to the Compose file), then variables from the environment entry override them.
+// Variables without values, which Compose passes from the host environment, are skipped.
+// Variable interpolation in the Compose file is not performed.
+func ReadCompose(file, service string) (*Dotenv, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := yaml.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	} else if len(docs) != 1 {
+		return nil, fmt.Errorf("%s: expected a single YAML document, got %d", file, len(docs))
+	}
+	m, _ := docs[0].(map[string]any)
+	services, _ := m["services"].(map[string]any)
+	if service == "" {
+		if len(services) != 1 {
+			var names []string
+			for name := range services {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("%s: expected a single service, select one of: %s", file, strings.Join(names, ", "))
+		}
+		for name := range services {
+			service = name
+		}
+	}
+	svc, ok := services[service].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: service %q not found", file, service)
+	}
+
+	// paths are relative to the directory of the Compose file
+	abs := func(p string) string {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(file), p)
+		}
+		return p
+	}
+	var files []string
+	switch v := svc["env_file"].(type) {
+	case string:
+		files = append(files, abs(v))
+	case []any:
+		for _, f := range v {
+			switch f := f.(type) {
+			case string:
+				files = append(files, abs(f))
+			case map[string]any:
+				p := abs(yamlString(f["path"]))
+				if req, ok := f["required"].(bool); ok && !req {
+					if _, err := os.Stat(p); err != nil {
+						continue
+					}
+				}
+				files = append(files, p)
+			}
+		}
+	}
+	d, err := ReadDotenv(files...)
+	if err != nil {
+		return nil, err
+	}
+	set := func(key, val string) {
+		d.vals[key] = dotenvValue{value: val, file: file}
+	}
+	switch v := svc["environment"].(type) {
+	case map[string]any:
+		for k, val := range v {
+			if val != nil {
+				set(k, yamlString(val))
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if k, val, ok := strings.Cut(yamlString(e), "="); ok {
+				set(k, val)
+			}
+		}
+	}
+	return d, nil
+}
+
+// yamlPath returns a value at a given path of mapping keys, or nil if it does not exist.
+func yamlPath(v any, keys ...string) any {
+	for _, k := range keys {
+		m, _ := v.(map[string]any)
+		v = m[k]
+	}
+	return v
+}
+
+// yamlString converts a YAML scalar to a string.
+func yamlString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// yamlStrings converts a YAML mapping to a map of strings.
+func yamlStrings(v any) map[string]string {
+	m, _ := v.(map[string]any)
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = yamlString(v)
+	}
+	return out
+}
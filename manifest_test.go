@@ -0,0 +1,204 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func dotenvValues(d *Dotenv) map[string]string {
+	out := make(map[string]string)
+	for _, k := range d.Keys() {
+		out[k], _, _ = d.Lookup(k)
+	}
+	return out
+}
+
+const k8sManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  HOST: db
+  PORT: "5432"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app-secret
+data:
+  PASSWORD: cGFzcw==
+stringData:
+  TOKEN: tok
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          envFrom:
+            - configMapRef:
+                name: app-config
+              prefix: DB_
+            - secretRef:
+                name: app-secret
+          env:
+            - name: DSN
+              value: postgres://$(DB_HOST):$(DB_PORT)/app
+            - name: PRICE
+              value: $$5 $(MISSING)
+            - name: DB_PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: app-secret
+                  key: PASSWORD
+            - name: LEVEL
+              valueFrom:
+                configMapKeyRef:
+                  name: other
+                  key: LEVEL
+            - name: POD
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.name
+        - name: sidecar
+          env:
+            - name: A
+              value: "1"
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: batch/v1
+    kind: CronJob
+    metadata:
+      name: backup
+    spec:
+      jobTemplate:
+        spec:
+          template:
+            spec:
+              containers:
+                - name: backup
+                  env:
+                    - name: SCHEDULE
+                      value: daily
+`
+
+func TestReadKubernetes(t *testing.T) {
+	file := writeTestFile(t, t.TempDir(), "k8s.yaml", k8sManifest)
+	d, err := ReadKubernetes(file, "Deployment/web", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]string{
+		"DB_HOST":     "db",
+		"DB_PORT":     "5432",
+		"PASSWORD":    "pass",
+		"TOKEN":       "tok",
+		"DSN":         "postgres://db:5432/app",
+		"PRICE":       "$5 $(MISSING)",
+		"DB_PASSWORD": "pass",
+	}
+	if got := dotenvValues(d); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected values:\n%v\nvs\n%v", got, exp)
+	}
+
+	d, err = ReadKubernetes(file, "backup", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := dotenvValues(d); !reflect.DeepEqual(got, map[string]string{"SCHEDULE": "daily"}) {
+		t.Fatalf("unexpected values: %v", got)
+	}
+
+	for _, c := range []struct {
+		workload, container, err string
+	}{
+		{"", "", "multiple workloads found, select one of: Deployment/web, CronJob/backup"},
+		{"Job/web", "", `workload "Job/web" not found`},
+		{"web", "", "multiple containers found, select one of: app, sidecar"},
+		{"web", "db", `container "db" not found`},
+	} {
+		if _, err := ReadKubernetes(file, c.workload, c.container); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s/%s: unexpected error: %v", c.workload, c.container, err)
+		}
+	}
+}
+
+func TestReadKubernetesInvalidSecret(t *testing.T) {
+	file := writeTestFile(t, t.TempDir(), "k8s.yaml", "kind: Secret\nmetadata:\n  name: s\ndata:\n  A: '!!'\n")
+	if _, err := ReadKubernetes(file, "", ""); err == nil || !strings.Contains(err.Error(), "secret s: key A") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReadCompose(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "base.env", "A=base\nB=base\n")
+	writeTestFile(t, dir, "extra.env", "B=extra\nC=extra\n")
+	abs := writeTestFile(t, t.TempDir(), "abs.env", "D=abs\n")
+	for _, c := range []struct {
+		name, compose string
+		exp           map[string]string
+		err           string
+	}{
+		{
+			name:    "string",
+			compose: "services:\n  app:\n    env_file: base.env\n    environment:\n      A: env\n      E:\n",
+			exp:     map[string]string{"A": "env", "B": "base"},
+		},
+		{
+			name:    "list",
+			compose: "services:\n  app:\n    env_file:\n      - base.env\n      - extra.env\n    environment:\n      - C=env\n      - E\n",
+			exp:     map[string]string{"A": "base", "B": "extra", "C": "env"},
+		},
+		{
+			name: "map",
+			compose: "services:\n  app:\n    env_file:\n      - path: base.env\n      - path: missing.env\n        required: false\n" +
+				"      - path: " + abs + "\n        required: false\n",
+			exp: map[string]string{"A": "base", "B": "base", "D": "abs"},
+		},
+		{
+			name:    "required",
+			compose: "services:\n  app:\n    env_file:\n      - path: missing.env\n",
+			err:     "missing.env",
+		},
+		{
+			name:    "multiple services",
+			compose: "services:\n  app: {}\n  db: {}\n",
+			err:     "select one of: app, db",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			file := writeTestFile(t, dir, "compose.yaml", c.compose)
+			d, err := ReadCompose(file, "")
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got := dotenvValues(d); !reflect.DeepEqual(got, c.exp) {
+				t.Fatalf("unexpected values: %v", got)
+			}
+		})
+	}
+}
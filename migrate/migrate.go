@@ -0,0 +1,243 @@
+// Package migrate implements versioned migrations of dotenv files.
+//
+// Migrations are declared in Go and applied in order to files that have a version lower than the migration version.
+// The version of the file is stored in the CONFIG_VERSION variable:
+//
+//	var migrations = []migrate.Migration{
+//		{Version: 1, Steps: []migrate.Step{
+//			migrate.Rename("TIMEOUT_MS", "TIMEOUT"),
+//			migrate.Transform("TIMEOUT", func(s string) (string, error) {
+//				ms, err := strconv.Atoi(s)
+//				return (time.Duration(ms) * time.Millisecond).String(), err
+//			}),
+//		}},
+//	}
+package migrate
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/env"
+)
+
+// VersionKey is the name of the variable that stores the version of the configuration.
+var VersionKey = "CONFIG_VERSION"
+
+// Step is a single change of a dotenv file.
+type Step func(f *env.DotenvFile) error
+
+// Migration is a set of steps that upgrade configuration to a given version.
+type Migration struct {
+	Version int
+	Name    string // optional description
+	Steps   []Step
+}
+
+// Rename changes the name of the variable, keeping its value.
+func Rename(key, name string) Step {
+	return func(f *env.DotenvFile) error {
+		f.Rename(key, name)
+		return nil
+	}
+}
+
+// Transform changes the value of the variable, if it's set.
+func Transform(key string, fnc func(s string) (string, error)) Step {
+	return func(f *env.DotenvFile) error {
+		s, ok := f.Get(key)
+		if !ok {
+			return nil
+		}
+		v, err := fnc(s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if v != s {
+			f.Set(key, v)
+		}
+		return nil
+	}
+}
+
+// Split replaces the variable with several new variables computed from its value, if it's set.
+func Split(key string, fnc func(s string) (map[string]string, error)) Step {
+	return func(f *env.DotenvFile) error {
+		s, ok := f.Get(key)
+		if !ok {
+			return nil
+		}
+		vals, err := fnc(s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		keys := make([]string, 0, len(vals))
+		for k := range vals {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f.Set(k, vals[k])
+		}
+		if _, ok := vals[key]; !ok {
+			f.Delete(key)
+		}
+		return nil
+	}
+}
+
+// Delete removes the variable.
+func Delete(key string) Step {
+	return func(f *env.DotenvFile) error {
+		f.Delete(key)
+		return nil
+	}
+}
+
+// Version returns the configuration version of the file, or zero if it's not set.
+func Version(f *env.DotenvFile) (int, error) {
+	s, ok := f.Get(VersionKey)
+	if !ok || s == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", VersionKey, err)
+	}
+	return v, nil
+}
+
+// Apply runs migrations with versions higher than the version of the file, in order, and updates the version.
+// It returns the number of applied migrations, and an error without applying any if two migrations have the same version.
+func Apply(f *env.DotenvFile, ms []Migration) (int, error) {
+	cur, err := Version(f)
+	if err != nil {
+		return 0, err
+	}
+	ms = append([]Migration(nil), ms...)
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	for i := 1; i < len(ms); i++ {
+		if ms[i].Version == ms[i-1].Version {
+			return 0, fmt.Errorf("migration %d is defined twice", ms[i].Version)
+		}
+	}
+	n := 0
+	for _, m := range ms {
+		if m.Version <= cur {
+			continue
+		}
+		for _, step := range m.Steps {
+			if err := step(f); err != nil {
+				return n, fmt.Errorf("migration %d: %w", m.Version, err)
+			}
+		}
+		cur = m.Version
+		f.Set(VersionKey, strconv.Itoa(cur))
+		n++
+	}
+	return n, nil
+}
+
+// Source applies migrations to a copy of variables from the source and returns the result.
+// It can be used to read configuration of an old version without changing the files.
+func Source(src env.Source, ms []Migration) (env.Source, error) {
+	f, err := env.ParseDotenvFile(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range src.Keys() {
+		if v, _, ok := src.Lookup(k); ok {
+			f.Set(k, v)
+		}
+	}
+	if _, err := Apply(f, ms); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Diff returns changes between two versions of a file, one variable per line:
+// "-KEY=old" for removed values and "+KEY=new" for added ones.
+func Diff(old, cur *env.DotenvFile) string {
+	keys := old.Keys()
+	for _, k := range cur.Keys() {
+		if _, ok := old.Get(k); !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		a, okA := old.Get(k)
+		b, okB := cur.Get(k)
+		if okA == okB && a == b {
+			continue
+		}
+		if okA {
+			fmt.Fprintf(&sb, "-%s=%s\n", k, a)
+		}
+		if okB {
+			fmt.Fprintf(&sb, "+%s=%s\n", k, b)
+		}
+	}
+	return sb.String()
+}
+
+// Run implements a command that upgrades dotenv files in place. Programs can expose it as a subcommand:
+//
+//	if len(os.Args) > 1 && os.Args[1] == "migrate-config" {
+//		if err := migrate.Run(os.Args[2:], migrations, os.Stdout); err != nil {
+//			log.Fatal(err)
+//		}
+//		return
+//	}
+//
+// With the -n flag, only the changes are printed and files are not modified.
+func Run(args []string, ms []Migration, w io.Writer) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dry := fs.Bool("n", false, "dry run: print changes without modifying files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: migrate [-n] FILE...")
+	}
+	for _, name := range fs.Args() {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		f, err := env.ParseDotenvFile(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		old := f.Clone()
+		n, err := Apply(f, ms)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if n == 0 {
+			fmt.Fprintf(w, "%s: up to date\n", name)
+			continue
+		}
+		ver, _ := Version(f)
+		fmt.Fprintf(w, "%s: applied %d migration(s), version %d\n%s", name, n, ver, Diff(old, f))
+		if *dry {
+			continue
+		}
+		st, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, f.Bytes(), st.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/dennwc/env"
+)
+
+func TestApply(t *testing.T) {
+	f, err := env.ParseDotenvFile([]byte("export TIMEOUT_MS=100\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := Apply(f, []Migration{
+		{Version: 2, Steps: []Step{Delete("OLD")}},
+		{Version: 1, Steps: []Step{Rename("TIMEOUT_MS", "TIMEOUT")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("expected 2 migrations to be applied, got %d", n)
+	}
+	if got, exp := string(f.Bytes()), "export TIMEOUT=100\nCONFIG_VERSION=2\n"; got != exp {
+		t.Fatalf("unexpected result: %q, expected %q", got, exp)
+	}
+}
+
+func TestApplyDuplicateVersions(t *testing.T) {
+	f, err := env.ParseDotenvFile([]byte("A=1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := Apply(f, []Migration{
+		{Version: 1, Steps: []Step{Rename("A", "B")}},
+		{Version: 2, Steps: []Step{Rename("B", "C")}},
+		{Version: 1, Steps: []Step{Rename("A", "D")}},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	} else if n != 0 || string(f.Bytes()) != "A=1\n" {
+		t.Fatalf("migrations are applied: %d, %q", n, f.Bytes())
+	}
+}
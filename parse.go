@@ -0,0 +1,150 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError is passed to Log when a variable has a value in a wrong format.
+//
+// For values in a wrong format, the error includes a hint with the accepted format and, for common mistakes,
+// a suggested fix: "30" for a duration suggests "30s", "10 MB" suggests "10MB", and "1,000" suggests "1000".
+type ParseError struct {
+	Key   string // variable name
+	Value string // raw value
+	Type  string // expected type, see Types
+	Err   error  // underlying error
+	Fix   string // suggested value, if the mistake is recognized
+	Hint  string // suggestion and the accepted format, if the value is in a wrong format
+}
+
+// Error returns the message of the underlying error, followed by the hint.
+func (e *ParseError) Error() string {
+	if e.Hint == "" {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + "; " + e.Hint
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func parseError(key, typ, val string, err error) *ParseError {
+	fix, hint := valueHint(typ, val)
+	return &ParseError{Key: key, Value: val, Type: typ, Err: err, Fix: fix, Hint: hint}
+}
+
+// parsers are functions used by getters for each of supported types.
+var parsers = map[string]func(s string) (any, error){
+	"string": func(s string) (any, error) { return s, nil },
+	"bool":   func(s string) (any, error) { return strconv.ParseBool(s) },
+	"int":    func(s string) (any, error) { return strconv.Atoi(s) },
+	"float64": func(s string) (any, error) {
+		return strconv.ParseFloat(s, 64)
+	},
+	"duration": func(s string) (any, error) { return time.ParseDuration(s) },
+	"bytes":    func(s string) (any, error) { return ParseBytes(s) },
+	"milli":    func(s string) (any, error) { return ParseQuantity(s) },
+	"quantity": func(s string) (any, error) { return parseQuantityValue(s) },
+	"strings": func(s string) (any, error) {
+		var out []string
+		for _, p := range strings.Split(s, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				out = append(out, p)
+			}
+		}
+		return out, nil
+	},
+}
+
+// Parse parses a raw value of the variable the same way as a getter for a given type does (see Types).
+// Errors are returned as *ParseError.
+func Parse(key, typ, s string) (any, error) {
+	p, ok := parsers[typ]
+	if !ok {
+		return nil, parseError(key, typ, s, fmt.Errorf("unsupported type %q", typ))
+	}
+	v, err := p(s)
+	if err != nil {
+		return nil, parseError(key, typ, s, err)
+	}
+	return v, nil
+}
+
+// Check parses a raw value of the variable and checks its constraints. Errors are returned as *ParseError.
+func (v Var) Check(s string) error {
+	typ := v.Type
+	if typ == "" {
+		typ = "string"
+	}
+	if s == "" {
+		if v.Required {
+			return parseError(v.Name, typ, s, fmt.Errorf("value is required"))
+		}
+		return nil
+	}
+	val, err := Parse(v.Name, typ, s)
+	if err != nil {
+		return err
+	}
+	if len(v.Enum) != 0 {
+		vals := []string{s}
+		if list, ok := val.([]string); ok {
+			// each element of a list must be one of the values
+			vals = list
+		}
+		for _, s := range vals {
+			found := false
+			for _, e := range v.Enum {
+				found = found || e == s
+			}
+			if !found {
+				return parseError(v.Name, typ, s, fmt.Errorf("must be one of %s", strings.Join(v.Enum, ", ")))
+			}
+		}
+	}
+	var f float64
+	switch val := val.(type) {
+	case int:
+		f = float64(val)
+	case int64:
+		f = float64(val)
+	case float64:
+		f = val
+	case time.Duration:
+		f = val.Seconds()
+	default:
+		return nil
+	}
+	if v.Min != nil && f < *v.Min {
+		return parseError(v.Name, typ, s, fmt.Errorf("must be at least %v", *v.Min))
+	}
+	if v.Max != nil && f > *v.Max {
+		return parseError(v.Name, typ, s, fmt.Errorf("must be at most %v", *v.Max))
+	}
+	return nil
+}
+
+// Normalize parses a raw value of the variable and formats it in a canonical form suitable for shell scripts:
+// booleans as "true" or "false", durations as a number of seconds, sizes as a number of bytes,
+// and quantities as integer values or milli-units.
+// Errors are returned as *ParseError.
+func Normalize(key, typ, s string) (string, error) {
+	v, err := Parse(key, typ, s)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case time.Duration:
+		return strconv.FormatFloat(v.Seconds(), 'f', -1, 64), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+	if v, ok := v.([]string); ok {
+		return strings.Join(v, ","), nil
+	}
+	return fmt.Sprint(v), nil
+}
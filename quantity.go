@@ -0,0 +1,135 @@
+package env
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	binarySuffixes  = []string{"Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+	decimalSuffixes = map[string]int{"n": -9, "u": -6, "m": -3, "": 0, "k": 3, "M": 6, "G": 9, "T": 12, "P": 15, "E": 18}
+)
+
+// ParseQuantity parses a Kubernetes resource quantity and returns its exact value in milli-units:
+// "500m" is 500, "1.5" is 1500 and "1Ki" is 1024000.
+//
+// Supported suffixes are binary (Ki, Mi, Gi, Ti, Pi, Ei), decimal (n, u, m, k, M, G, T, P, E)
+// and exponents ("1e3", "1.5E-3"). Values with a precision finer than a milli-unit are rounded up, as Kubernetes does.
+func ParseQuantity(s string) (int64, error) {
+	v, err := parseQuantity(s)
+	if err != nil {
+		return 0, err
+	}
+	return ceilInt64(s, v.Mul(v, big.NewRat(1000, 1)))
+}
+
+// parseQuantity parses a quantity as an exact rational number.
+func parseQuantity(s string) (*big.Rat, error) {
+	num, mult, err := splitQuantity(s)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := new(big.Rat).SetString(num)
+	if !ok {
+		return nil, errors.New("invalid quantity " + strconv.Quote(s))
+	}
+	return v.Mul(v, mult), nil
+}
+
+// ceilInt64 rounds the value up to an integer.
+func ceilInt64(s string, v *big.Rat) (int64, error) {
+	n := new(big.Int).Quo(v.Num(), v.Denom())
+	if new(big.Rat).SetInt(n).Cmp(v) < 0 {
+		n.Add(n, big.NewInt(1))
+	}
+	if !n.IsInt64() {
+		return 0, errors.New("quantity " + strconv.Quote(s) + " is too large")
+	}
+	return n.Int64(), nil
+}
+
+// splitQuantity splits the quantity into a number and a multiplier for its suffix.
+func splitQuantity(s string) (string, *big.Rat, error) {
+	bad := errors.New("invalid quantity " + strconv.Quote(s))
+	i := 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	digits := 0
+	for ; i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.'); i++ {
+		if s[i] != '.' {
+			digits++
+		}
+	}
+	if digits == 0 || strings.Count(s[:i], ".") > 1 {
+		return "", nil, bad
+	}
+	num, suffix := s[:i], s[i:]
+	for j, b := range binarySuffixes {
+		if suffix == b {
+			return num, new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(10*(j+1)))), nil
+		}
+	}
+	exp, ok := decimalSuffixes[suffix]
+	if !ok {
+		if len(suffix) < 2 || (suffix[0] != 'e' && suffix[0] != 'E') {
+			return "", nil, bad
+		}
+		e, err := strconv.Atoi(suffix[1:])
+		if err != nil || e < -18 || e > 18 {
+			return "", nil, bad
+		}
+		exp = e
+	}
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil)
+	if exp < 0 {
+		return num, new(big.Rat).SetFrac(big.NewInt(1), p), nil
+	}
+	return num, new(big.Rat).SetInt(p), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// FormatQuantity formats milli-units as a canonical Kubernetes quantity.
+//
+// If binary is set, the value is formatted with the largest binary suffix that represents it exactly ("256Mi"),
+// when possible. Otherwise, or if the value is not a whole multiple of 1024, the largest decimal suffix
+// that represents it exactly is used ("500m", "1500m", "2k").
+func FormatQuantity(milli int64, binary bool) string {
+	if milli%1000 == 0 {
+		v := milli / 1000
+		if binary && v != 0 {
+			for i := len(binarySuffixes) - 1; i >= 0; i-- {
+				if d := int64(1) << (10 * (i + 1)); v%d == 0 {
+					return strconv.FormatInt(v/d, 10) + binarySuffixes[i]
+				}
+			}
+		}
+		for _, u := range []struct {
+			d    int64
+			name string
+		}{{1e18, "E"}, {1e15, "P"}, {1e12, "T"}, {1e9, "G"}, {1e6, "M"}, {1e3, "k"}} {
+			if v != 0 && v%u.d == 0 {
+				return strconv.FormatInt(v/u.d, 10) + u.name
+			}
+		}
+		return strconv.FormatInt(v, 10)
+	}
+	return strconv.FormatInt(milli, 10) + "m"
+}
+
+// parseQuantityValue parses a quantity and returns its integer value, rounded up.
+func parseQuantityValue(s string) (int64, error) {
+	v, err := parseQuantity(s)
+	if err != nil {
+		return 0, err
+	}
+	return ceilInt64(s, v)
+}
@@ -0,0 +1,118 @@
+package env
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	for _, c := range []struct {
+		in  string
+		exp int64
+		err bool
+	}{
+		{in: "0", exp: 0},
+		{in: "2", exp: 2000},
+		{in: "+1", exp: 1000},
+		{in: "1.5", exp: 1500},
+		{in: ".5", exp: 500},
+		{in: "500m", exp: 500},
+		{in: "-500m", exp: -500},
+		{in: "1k", exp: 1e6},
+		{in: "1M", exp: 1e9},
+		{in: "1Ki", exp: 1024000},
+		{in: "1Gi", exp: 1 << 30 * 1000},
+		{in: "1e3", exp: 1e6},
+		{in: "1E3", exp: 1e6},
+		{in: "1.5E-3", exp: 2},
+		{in: "1n", exp: 1},
+		{in: "1u", exp: 1},
+		{in: "-1.5n", exp: 0},
+		{in: "", err: true},
+		{in: "m", err: true},
+		{in: "Ki", err: true},
+		{in: "1.2.3", err: true},
+		{in: "1x", err: true},
+		{in: "1KiB", err: true},
+		{in: "1 Ki", err: true},
+		{in: "1e", err: true},
+		{in: "1e19", err: true},
+		{in: "9E", err: true},
+		{in: "8Ei", err: true},
+	} {
+		n, err := ParseQuantity(c.in)
+		if c.err {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %d", c.in, n)
+			}
+		} else if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+		} else if n != c.exp {
+			t.Errorf("%q: got %d, expected %d", c.in, n, c.exp)
+		}
+	}
+}
+
+func TestParseQuantityValue(t *testing.T) {
+	for _, c := range []struct {
+		in  string
+		exp int64
+	}{
+		{"1Ki", 1024},
+		{"256Mi", 256 << 20},
+		{"1.5", 2},
+		{"100m", 1},
+		{"2k", 2000},
+		{"-1.5", -1},
+	} {
+		v, err := Parse("Q", "quantity", c.in)
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+		} else if v != c.exp {
+			t.Errorf("%q: got %v, expected %d", c.in, v, c.exp)
+		}
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	for _, c := range []struct {
+		milli  int64
+		binary bool
+		exp    string
+	}{
+		{0, false, "0"},
+		{0, true, "0"},
+		{500, false, "500m"},
+		{1500, true, "1500m"},
+		{2000, false, "2"},
+		{2e6, false, "2k"},
+		{2e6, true, "2k"},
+		{1024000, false, "1024"},
+		{1024000, true, "1Ki"},
+		{256 << 20 * 1000, true, "256Mi"},
+		{3 << 30 * 1000, true, "3Gi"},
+		{1e9, true, "1M"},
+		{-500, false, "-500m"},
+		{-1024000, true, "-1Ki"},
+		{math.MaxInt64, false, "9223372036854775807m"},
+	} {
+		if got := FormatQuantity(c.milli, c.binary); got != c.exp {
+			t.Errorf("%d, %v: got %q, expected %q", c.milli, c.binary, got, c.exp)
+		}
+	}
+}
+
+func TestQuantityRoundTrip(t *testing.T) {
+	for _, milli := range []int64{
+		0, 1, 500, 999, 1000, 1500, 2000, 1023000, 1024000, 1e6, 1e9, 1e18,
+		256 << 20 * 1000, 3 << 30 * 1000, 5 << 50 * 1000, -1, -2000, -1024000,
+		math.MaxInt64, math.MinInt64 + 1,
+	} {
+		for _, binary := range []bool{false, true} {
+			s := FormatQuantity(milli, binary)
+			if n, err := ParseQuantity(s); err != nil || n != milli {
+				t.Errorf("%d, %v: formatted as %q, parsed as %d (%v)", milli, binary, s, n, err)
+			}
+		}
+	}
+}
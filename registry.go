@@ -0,0 +1,97 @@
+package env
+
+import (
+	"sort"
+	"sync"
+)
+
+// Var describes a variable used by the program.
+type Var struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`
+	// Group is set for variables of tagged union variants, for example "STORAGE=s3" (see RegisterUnion).
+	Group string `json:"group,omitempty"`
+
+	// Constraints. Min and Max are compared with numeric values: durations are in seconds and sizes are in bytes.
+	// For lists, each element must be one of Enum values.
+
+	Required bool     `json:"required,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Enum     []string `json:"enum,omitempty"`
+}
+
+var (
+	regMu    sync.RWMutex
+	registry = make(map[string]Var)
+)
+
+// Declare adds a description of the variable to the registry.
+//
+// Getters register variables automatically with their type and default, but without a description.
+// Fields that are set in v take precedence over the ones registered by getters.
+func Declare(v Var) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	cur := registry[v.Name]
+	if v.Type == "" {
+		v.Type = cur.Type
+	}
+	if v.Default == "" {
+		v.Default = cur.Default
+	}
+	if v.Description == "" {
+		v.Description = cur.Description
+	}
+	if v.Group == "" {
+		v.Group = cur.Group
+	}
+	v.Secret = v.Secret || cur.Secret
+	v.Required = v.Required || cur.Required
+	if v.Min == nil {
+		v.Min = cur.Min
+	}
+	if v.Max == nil {
+		v.Max = cur.Max
+	}
+	if v.Enum == nil {
+		v.Enum = cur.Enum
+	}
+	registry[v.Name] = v
+}
+
+// declare registers the variable read by a getter, unless it was already declared.
+func declare(key, typ, def string) {
+	regMu.RLock()
+	cur, ok := registry[key]
+	regMu.RUnlock()
+	if ok && cur.Type != "" && cur.Default != "" {
+		return
+	}
+	regMu.Lock()
+	defer regMu.Unlock()
+	cur = registry[key]
+	cur.Name = key
+	if cur.Type == "" {
+		cur.Type = typ
+	}
+	if cur.Default == "" {
+		cur.Default = def
+	}
+	registry[key] = cur
+}
+
+// Vars returns all variables declared or read by the program, sorted by name.
+func Vars() []Var {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	out := make([]Var, 0, len(registry))
+	for _, v := range registry {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,182 @@
+package env
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Value is a resolved variable value along with the place it was found at.
+type Value struct {
+	Key    string // variable that was requested
+	Value  string // raw value of the variable
+	From   string // variable or override that provided the value
+	Source string // where the value came from, for example "env", "override" or a file name
+}
+
+// Hostname returns the name of the current instance. It is used to select instance-specific overrides.
+// Can be changed to use a different instance name.
+var Hostname = func() string {
+	if h, err := os.Hostname(); err == nil && h != "" {
+		return h
+	}
+	return os.Getenv("HOSTNAME")
+}
+
+type override struct {
+	glob  string
+	value string
+}
+
+var (
+	overMu    sync.RWMutex
+	overrides = make(map[string][]override)
+)
+
+// Override sets a value of the variable for instances with a hostname matching the glob (see path.Match).
+// Overrides registered first take precedence.
+func Override(hostGlob, key, value string) {
+	overMu.Lock()
+	defer overMu.Unlock()
+	overrides[key] = append(overrides[key], override{glob: hostGlob, value: value})
+}
+
+// PodName returns the name of the Kubernetes pod the process runs in. It is used to find the StatefulSet ordinal.
+//
+// By default it returns POD_NAME from the process environment, which is usually set with the downward API:
+//
+//	env:
+//	- name: POD_NAME
+//	  valueFrom:
+//	    fieldRef:
+//	      fieldPath: metadata.name
+//
+// The hostname is not used for this, since names like "ip-10-0-0-5" or "proj-web-1" are common outside of
+// StatefulSets. Can be changed to use a different pod name.
+var PodName = func() string {
+	return os.Getenv("POD_NAME")
+}
+
+// Ordinal returns the ordinal of the current instance, as set by Kubernetes StatefulSet in the pod name ("web-2").
+// It returns false if the pod name is not set (see PodName) or does not end with an ordinal.
+func Ordinal() (int, bool) {
+	name := PodName()
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(name[i+1:])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// selectorName converts a hostname to a form that can be used in a variable name.
+func selectorName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// Lookup finds the effective value of a variable in the process environment and sources added with Use.
+// Empty variables are considered unset.
+//
+// Instance-specific values take precedence over the variable itself, in this order:
+//
+//	KEY__HOST_<hostname>  - hostname with all non-alphanumeric characters replaced by '_' ("KEY__HOST_web_2")
+//	KEY__ORDINAL_<n>      - StatefulSet pod ordinal, only if POD_NAME is set ("KEY__ORDINAL_2", see Ordinal)
+//	Override(glob, KEY)   - overrides set in code for matching hostnames
+//
+// Sources added with UseDefaults are only checked, in the same order, if the variable is not set in the process
+// environment or in sources added with Use: "KEY__ORDINAL_0" in defaults does not override "KEY" in the environment.
+//
+// If the variable is not set, but KEY_FILE is, the value is read from the file it points to,
+// with a trailing newline ("\n" or "\r\n") removed. This is commonly used for secrets mounted as files.
+// KEY_FILE in the process environment or in sources added with Use takes precedence over KEY in defaults
+// (see UseDefaults). The file is read on each call, and KEY_FILE is looked up in all sources, so only use
+// sources that are trusted to point at files readable by the process.
+func Lookup(key string) (Value, bool) {
+	sel := selectors{host: Hostname()}
+	sel.ord, sel.hasOrd = Ordinal()
+	srcMu.RLock()
+	env, defs := environment(), defaults
+	srcMu.RUnlock()
+	if v, ok, set := lookupLayer(env, key, sel, true); set {
+		return v, ok
+	}
+	if v, ok, set := lookupLayer(defs, key, sel, false); set {
+		return v, ok
+	}
+	return Value{Key: key}, false
+}
+
+// selectors describe the current instance. They are found once per Lookup and shared by all layers.
+type selectors struct {
+	host   string
+	ord    int
+	hasOrd bool
+}
+
+// lookupLayer resolves the variable in the list of sources, as described in Lookup.
+// Overrides are only checked if withOverrides is set. It reports if the variable is set in the list,
+// even if its value cannot be read.
+func lookupLayer(list []Source, key string, sel selectors, withOverrides bool) (v Value, ok, set bool) {
+	if sel.host != "" {
+		k := key + "__HOST_" + selectorName(sel.host)
+		if s, raw, src, ok := lookupIn(list, k); ok {
+			return Value{Key: key, Value: s, From: raw, Source: src}, true, true
+		}
+	}
+	if sel.hasOrd {
+		k := key + "__ORDINAL_" + strconv.Itoa(sel.ord)
+		if s, raw, src, ok := lookupIn(list, k); ok {
+			return Value{Key: key, Value: s, From: raw, Source: src}, true, true
+		}
+	}
+	if withOverrides {
+		overMu.RLock()
+		over := overrides[key]
+		overMu.RUnlock()
+		for _, o := range over {
+			if ok, _ := path.Match(o.glob, sel.host); ok && o.value != "" {
+				return Value{Key: key, Value: o.value, From: key + "@" + o.glob, Source: "override"}, true, true
+			}
+		}
+	}
+	if s, raw, src, ok := lookupIn(list, key); ok {
+		return Value{Key: key, Value: s, From: raw, Source: src}, true, true
+	}
+	return lookupFile(list, key)
+}
+
+// lookupFile reads the variable from a file pointed to by KEY_FILE in one of the sources.
+// It reports if KEY_FILE is set, even if the file cannot be read.
+func lookupFile(list []Source, key string) (v Value, ok, set bool) {
+	v = Value{Key: key}
+	if strings.HasSuffix(key, "_FILE") {
+		return v, false, false
+	}
+	name, raw, _, set := lookupIn(list, key+"_FILE")
+	if !set {
+		return v, false, false
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		logError(key, err)
+		return v, false, true
+	}
+	s := string(data)
+	if strings.HasSuffix(s, "\r\n") {
+		s = s[:len(s)-2]
+	} else {
+		s = strings.TrimSuffix(s, "\n")
+	}
+	v.Value, v.From, v.Source = s, raw, name
+	return v, s != "", true
+}
@@ -0,0 +1,185 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookupFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for _, c := range []struct {
+		name string
+		vals Map
+		exp  string
+		ok   bool
+	}{
+		{name: "newline", vals: Map{"A_FILE": write("lf", "secret\n")}, exp: "secret", ok: true},
+		{name: "crlf", vals: Map{"A_FILE": write("crlf", "secret\r\n")}, exp: "secret", ok: true},
+		{name: "no newline", vals: Map{"A_FILE": write("raw", "secret")}, exp: "secret", ok: true},
+		{name: "one newline", vals: Map{"A_FILE": write("two", "secret\n\n")}, exp: "secret\n", ok: true},
+		{name: "empty", vals: Map{"A_FILE": write("empty", "\n")}},
+		{name: "variable wins", vals: Map{"A": "value", "A_FILE": write("lf2", "secret\n")}, exp: "value", ok: true},
+		{name: "missing file", vals: Map{"A_FILE": filepath.Join(dir, "missing")}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			testEnv(t, c.vals)
+			v, ok := Lookup("A")
+			if ok != c.ok || v.Value != c.exp {
+				t.Fatalf("unexpected result: %q, %v", v.Value, ok)
+			}
+			if got := String("A", "def"); c.ok && got != c.exp {
+				t.Fatalf("unexpected String result: %q", got)
+			}
+		})
+	}
+}
+
+func TestLookupFileNotRecursive(t *testing.T) {
+	testEnv(t, Map{"A_FILE_FILE": "/nonexistent"})
+	if v, ok := Lookup("A_FILE"); ok {
+		t.Fatalf("unexpected value: %+v", v)
+	}
+}
+
+func TestLookupFilePrecedence(t *testing.T) {
+	pass := filepath.Join(t.TempDir(), "pw")
+	if err := os.WriteFile(pass, []byte("prod\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	testEnv(t, Map{"DB_PASSWORD_FILE": pass})
+	UseDefaults(Map{"DB_PASSWORD": "dev", "DB_USER": "app", "DB_HOST_FILE": pass})
+	for _, c := range []struct {
+		key, exp, source string
+	}{
+		{"DB_PASSWORD", "prod", pass},
+		{"DB_USER", "app", "map"},
+		{"DB_HOST", "prod", pass},
+	} {
+		if v, ok := Lookup(c.key); !ok || v.Value != c.exp || v.Source != c.source {
+			t.Errorf("%s: unexpected value: %+v", c.key, v)
+		}
+	}
+}
+
+func TestLookupFileUnreadable(t *testing.T) {
+	testEnv(t, Map{"DB_PASSWORD_FILE": filepath.Join(t.TempDir(), "missing")})
+	UseDefaults(Map{"DB_PASSWORD": "dev"})
+	// an embedded default must not replace a secret that cannot be read
+	if v, ok := Lookup("DB_PASSWORD"); ok {
+		t.Fatalf("unexpected value: %+v", v)
+	}
+}
+
+func withHostname(t *testing.T, host string) {
+	old := Hostname
+	Hostname = func() string { return host }
+	t.Cleanup(func() { Hostname = old })
+}
+
+func withPodName(t *testing.T, name string) {
+	old := PodName
+	PodName = func() string { return name }
+	t.Cleanup(func() { PodName = old })
+}
+
+func TestOrdinal(t *testing.T) {
+	withHostname(t, "ip-10-0-0-5")
+	for _, c := range []struct {
+		pod string
+		exp int
+		ok  bool
+	}{
+		{pod: ""},
+		{pod: "web"},
+		{pod: "web-x"},
+		{pod: "web-1a"},
+		{pod: "web-0", exp: 0, ok: true},
+		{pod: "my-web-12", exp: 12, ok: true},
+	} {
+		withPodName(t, c.pod)
+		if n, ok := Ordinal(); n != c.exp || ok != c.ok {
+			t.Errorf("%q: got %d, %v", c.pod, n, ok)
+		}
+	}
+}
+
+func TestLookupSelectors(t *testing.T) {
+	withHostname(t, "web-2.local")
+	withPodName(t, "web-2")
+	testEnv(t, Map{
+		"A":                   "plain",
+		"A__HOST_web_2_local": "host",
+		"A__ORDINAL_2":        "ordinal",
+		"B":                   "plain",
+		"B__ORDINAL_2":        "ordinal",
+		"B__ORDINAL_1":        "other",
+		"C":                   "plain",
+		"C__HOST_web_1":       "other",
+		"D__ORDINAL_2":        "",
+		"D":                   "plain",
+	})
+	Override("web-*", "A", "override")
+	Override("web-*", "B", "override")
+	Override("db-*", "C", "other")
+	Override("web-2*", "C", "override")
+	Override("web-*", "C", "late")
+	Override("*", "E", "override")
+	for _, c := range []struct {
+		key, exp, from, source string
+	}{
+		{"A", "host", "A__HOST_web_2_local", "map"},
+		{"B", "ordinal", "B__ORDINAL_2", "map"},
+		{"C", "override", "C@web-2*", "override"},
+		// empty selectors are ignored
+		{"D", "plain", "D", "map"},
+		{"E", "override", "E@*", "override"},
+	} {
+		v, ok := Lookup(c.key)
+		if !ok || v != (Value{Key: c.key, Value: c.exp, From: c.from, Source: c.source}) {
+			t.Errorf("%s: unexpected value: %+v", c.key, v)
+		}
+	}
+}
+
+func TestLookupHostnameOrdinal(t *testing.T) {
+	// hostnames ending with a number are not StatefulSet ordinals unless POD_NAME is set
+	withHostname(t, "ip-10-0-0-5")
+	withPodName(t, "")
+	testEnv(t, Map{"A": "plain", "A__ORDINAL_5": "ordinal"})
+	if v, ok := Lookup("A"); !ok || v.Value != "plain" {
+		t.Fatalf("unexpected value: %+v", v)
+	}
+}
+
+func TestLookupDefaultsPrecedence(t *testing.T) {
+	withHostname(t, "web-0")
+	withPodName(t, "web-0")
+	testEnv(t, Map{"TIMEOUT": "5s", "PORT__HOST_web_0": "81"})
+	UseDefaults(Map{
+		"TIMEOUT__ORDINAL_0": "1s",
+		"PORT":               "80",
+		"NAME":               "app",
+		"NAME__ORDINAL_0":    "app-0",
+	})
+	for _, c := range []struct {
+		key, exp, from string
+	}{
+		// selectors in defaults do not override the environment
+		{"TIMEOUT", "5s", "TIMEOUT"},
+		{"PORT", "81", "PORT__HOST_web_0"},
+		// but are used for defaults themselves
+		{"NAME", "app-0", "NAME__ORDINAL_0"},
+	} {
+		if v, ok := Lookup(c.key); !ok || v.Value != c.exp || v.From != c.from {
+			t.Errorf("%s: unexpected value: %+v", c.key, v)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// RuntimeSettings describes Go runtime settings applied by ApplyRuntime.
+type RuntimeSettings struct {
+	MaxProcs    int   // GOMAXPROCS value; zero if it was not changed
+	GCPercent   int   // GC percent value; only valid if GCSet is true, -1 means GC is off
+	GCSet       bool  // GCPercent was changed
+	MemoryLimit int64 // soft memory limit in bytes; zero if it was not changed
+}
+
+// String returns applied settings in the same form as Go runtime environment variables.
+func (s RuntimeSettings) String() string {
+	var parts []string
+	if s.MaxProcs > 0 {
+		parts = append(parts, "GOMAXPROCS="+strconv.Itoa(s.MaxProcs))
+	}
+	if s.GCSet {
+		if s.GCPercent < 0 {
+			parts = append(parts, "GOGC=off")
+		} else {
+			parts = append(parts, "GOGC="+strconv.Itoa(s.GCPercent))
+		}
+	}
+	if s.MemoryLimit > 0 {
+		parts = append(parts, "GOMEMLIMIT="+strconv.FormatInt(s.MemoryLimit, 10))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ApplyRuntime reads Go runtime settings from prefixed variables and applies them.
+// For example, with prefix "APP_" it will read:
+//
+//	APP_GOMAXPROCS    - number of OS threads, see runtime.GOMAXPROCS
+//	APP_GOGC          - GC percent or "off", see debug.SetGCPercent
+//	APP_MEMORY_LIMIT  - byte size ("512MiB", see ParseBytes) or percentage of cgroup memory limit ("80%"), see debug.SetMemoryLimit
+//
+// Variables that are empty are ignored. Invalid values are reported with Log and are not applied.
+func ApplyRuntime(prefix string) RuntimeSettings {
+	Declare(Var{Name: prefix + "GOMAXPROCS", Type: "int", Description: "Maximum number of OS threads executing Go code."})
+	Declare(Var{Name: prefix + "GOGC", Type: "string", Description: "Go GC percent, or \"off\" to disable GC."})
+	Declare(Var{Name: prefix + "MEMORY_LIMIT", Type: "string", Description: "Go soft memory limit, as a byte size or a percentage of cgroup memory limit."})
+	var out RuntimeSettings
+	key := prefix + "GOMAXPROCS"
+	if s := raw(key); s != "" {
+		if n, err := strconv.Atoi(s); err != nil {
+			logError(key, parseError(key, "int", s, err))
+		} else if n < 1 {
+			logError(key, parseError(key, "int", s, fmt.Errorf("GOMAXPROCS must be positive, got %d", n)))
+		} else {
+			runtime.GOMAXPROCS(n)
+			out.MaxProcs = n
+		}
+	}
+	key = prefix + "GOGC"
+	if s := raw(key); strings.EqualFold(s, "off") {
+		debug.SetGCPercent(-1)
+		out.GCPercent, out.GCSet = -1, true
+	} else if s != "" {
+		if n, err := strconv.Atoi(s); err != nil {
+			logError(key, parseError(key, "int", s, err))
+		} else if n < 0 {
+			logError(key, parseError(key, "int", s, fmt.Errorf("GOGC must be non-negative or \"off\", got %d", n)))
+		} else {
+			debug.SetGCPercent(n)
+			out.GCPercent, out.GCSet = n, true
+		}
+	}
+	key = prefix + "MEMORY_LIMIT"
+	if s := raw(key); s != "" {
+		if n, err := parseMemoryLimit(s, cgroupMemoryLimit); err != nil {
+			logError(key, parseError(key, "bytes", s, err))
+		} else {
+			debug.SetMemoryLimit(n)
+			out.MemoryLimit = n
+		}
+	}
+	return out
+}
+
+// parseMemoryLimit parses a byte size or a percentage of the limit returned by cgroupLimit.
+func parseMemoryLimit(s string, cgroupLimit func() (int64, error)) (int64, error) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return 0, err
+		}
+		if f <= 0 || f > 100 {
+			return 0, fmt.Errorf("memory limit percentage must be in (0, 100], got %v", f)
+		}
+		lim, err := cgroupLimit()
+		if err != nil {
+			return 0, err
+		}
+		return int64(float64(lim) * f / 100), nil
+	}
+	n, err := ParseBytes(s)
+	if err != nil {
+		return 0, err
+	}
+	if n <= 0 {
+		return 0, errors.New("memory limit must be positive")
+	}
+	return n, nil
+}
+
+// cgroupMemoryLimit returns memory limit of the cgroup of the current process (v2 or v1).
+func cgroupMemoryLimit() (int64, error) {
+	return cgroupMemoryLimitFS(os.DirFS("/"))
+}
+
+// cgroupMemoryLimitFS finds the cgroup of the current process in /proc/self/cgroup and returns the lowest memory
+// limit of it and its parents, since all of them apply. The fsys must be the root file system.
+//
+// Without a cgroup namespace, the process cgroup is a subdirectory of /sys/fs/cgroup. In a namespace,
+// or if /proc/self/cgroup cannot be read, the limit of /sys/fs/cgroup itself is used.
+func cgroupMemoryLimitFS(fsys fs.FS) (int64, error) {
+	v2, v1 := "/", "/"
+	if data, err := fs.ReadFile(fsys, "proc/self/cgroup"); err == nil {
+		// lines are in the form "hierarchy-ID:controller-list:cgroup-path"
+		for _, line := range strings.Split(string(data), "\n") {
+			parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			if parts[0] == "0" && parts[1] == "" {
+				v2 = parts[2]
+				continue
+			}
+			for _, c := range strings.Split(parts[1], ",") {
+				if c == "memory" {
+					v1 = parts[2]
+				}
+			}
+		}
+	}
+	for _, h := range []struct {
+		root, cgroup, file string
+	}{
+		{"sys/fs/cgroup", v2, "memory.max"},
+		{"sys/fs/cgroup/memory", v1, "memory.limit_in_bytes"},
+	} {
+		lim := int64(-1)
+		// paths outside of the namespace are shown with "..", these are resolved to the namespace root
+		for p := path.Clean("/" + h.cgroup); ; p = path.Dir(p) {
+			n, ok, err := readCgroupLimit(fsys, path.Join(h.root, p, h.file))
+			if err != nil {
+				return 0, err
+			}
+			if ok && (lim < 0 || n < lim) {
+				lim = n
+			}
+			if p == "/" {
+				break
+			}
+		}
+		if lim >= 0 {
+			return lim, nil
+		}
+	}
+	return 0, errors.New("cgroup memory limit is not set")
+}
+
+// readCgroupLimit reads a cgroup memory limit file. It returns false if the file does not exist or there is no limit.
+func readCgroupLimit(fsys fs.FS, name string) (int64, bool, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return 0, false, nil
+	}
+	s := strings.TrimSpace(string(data))
+	if s == "max" {
+		return 0, false, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot parse cgroup memory limit: %w", err)
+	}
+	// cgroup v1 reports a huge number instead of "max"
+	if n >= 1<<62 {
+		return 0, false, nil
+	}
+	return n, true, nil
+}
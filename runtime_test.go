@@ -0,0 +1,193 @@
+package env
+
+import (
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCgroupMemoryLimit(t *testing.T) {
+	file := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	for _, c := range []struct {
+		name string
+		fs   fstest.MapFS
+		exp  int64 // -1 if the limit is not set
+		err  bool
+	}{
+		{
+			name: "v2 namespace",
+			fs: fstest.MapFS{
+				"proc/self/cgroup":         file("0::/\n"),
+				"sys/fs/cgroup/memory.max": file("1073741824\n"),
+			},
+			exp: 1 << 30,
+		},
+		{
+			name: "v2 own cgroup",
+			fs: fstest.MapFS{
+				"proc/self/cgroup": file("0::/system.slice/app.service\n"),
+				"sys/fs/cgroup/system.slice/app.service/memory.max": file("536870912\n"),
+				"sys/fs/cgroup/system.slice/memory.max":             file("max\n"),
+			},
+			exp: 512 << 20,
+		},
+		{
+			name: "v2 parent limit",
+			fs: fstest.MapFS{
+				"proc/self/cgroup":                           file("0::/kubepods/pod1/ctr\n"),
+				"sys/fs/cgroup/kubepods/pod1/ctr/memory.max": file("max\n"),
+				"sys/fs/cgroup/kubepods/pod1/memory.max":     file("268435456\n"),
+				"sys/fs/cgroup/kubepods/memory.max":          file("1073741824\n"),
+			},
+			exp: 256 << 20,
+		},
+		{
+			name: "v2 outside of namespace",
+			fs: fstest.MapFS{
+				"proc/self/cgroup":         file("0::/../../other\n"),
+				"sys/fs/cgroup/memory.max": file("1048576\n"),
+			},
+			exp: 1 << 20,
+		},
+		{
+			name: "v2 no limit",
+			fs: fstest.MapFS{
+				"proc/self/cgroup":                    file("0::/user.slice\n"),
+				"sys/fs/cgroup/user.slice/memory.max": file("max\n"),
+			},
+			exp: -1,
+		},
+		{
+			name: "v1",
+			fs: fstest.MapFS{
+				"proc/self/cgroup": file("5:cpuacct,cpu:/docker/abc\n4:memory:/docker/abc\n0::/\n"),
+				"sys/fs/cgroup/memory/docker/abc/memory.limit_in_bytes": file("2097152\n"),
+				"sys/fs/cgroup/memory/memory.limit_in_bytes":            file("9223372036854771712\n"),
+			},
+			exp: 2 << 20,
+		},
+		{
+			name: "v1 container root",
+			fs: fstest.MapFS{
+				"proc/self/cgroup":                           file("4:memory:/docker/abc\n"),
+				"sys/fs/cgroup/memory/memory.limit_in_bytes": file("4194304\n"),
+			},
+			exp: 4 << 20,
+		},
+		{
+			name: "no proc",
+			fs: fstest.MapFS{
+				"sys/fs/cgroup/memory.max": file("1048576\n"),
+			},
+			exp: 1 << 20,
+		},
+		{
+			name: "invalid",
+			fs: fstest.MapFS{
+				"sys/fs/cgroup/memory.max": file("lots\n"),
+			},
+			err: true,
+		},
+		{
+			name: "empty",
+			fs:   fstest.MapFS{},
+			exp:  -1,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			n, err := cgroupMemoryLimitFS(c.fs)
+			switch {
+			case c.err:
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+			case c.exp < 0:
+				if err == nil {
+					t.Fatalf("unexpected limit: %d", n)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case n != c.exp:
+				t.Fatalf("got %d, expected %d", n, c.exp)
+			}
+		})
+	}
+}
+
+func TestParseMemoryLimit(t *testing.T) {
+	limit := func() (int64, error) { return 1000, nil }
+	noLimit := func() (int64, error) { return 0, errors.New("no limit") }
+	for _, c := range []struct {
+		in     string
+		cgroup func() (int64, error)
+		exp    int64
+		err    bool
+	}{
+		{in: "512MiB", cgroup: noLimit, exp: 512 << 20},
+		{in: "1000", cgroup: noLimit, exp: 1000},
+		{in: "80%", cgroup: limit, exp: 800},
+		{in: "12.5 %", cgroup: limit, exp: 125},
+		{in: "100%", cgroup: limit, exp: 1000},
+		{in: "80%", cgroup: noLimit, err: true},
+		{in: "0%", cgroup: limit, err: true},
+		{in: "101%", cgroup: limit, err: true},
+		{in: "x%", cgroup: limit, err: true},
+		{in: "0", cgroup: limit, err: true},
+		{in: "-1KiB", cgroup: limit, err: true},
+		{in: "lots", cgroup: limit, err: true},
+	} {
+		n, err := parseMemoryLimit(c.in, c.cgroup)
+		if c.err {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %d", c.in, n)
+			}
+		} else if err != nil || n != c.exp {
+			t.Errorf("%q: got %d, %v, expected %d", c.in, n, err, c.exp)
+		}
+	}
+}
+
+func TestApplyRuntime(t *testing.T) {
+	oldProcs := runtime.GOMAXPROCS(0)
+	oldGC := debug.SetGCPercent(100)
+	oldLimit := debug.SetMemoryLimit(-1)
+	t.Cleanup(func() {
+		runtime.GOMAXPROCS(oldProcs)
+		debug.SetGCPercent(oldGC)
+		debug.SetMemoryLimit(oldLimit)
+	})
+
+	testEnv(t, Map{"APP_GOMAXPROCS": "3", "APP_GOGC": "off", "APP_MEMORY_LIMIT": "64MiB"})
+	s := ApplyRuntime("APP_")
+	if exp := (RuntimeSettings{MaxProcs: 3, GCPercent: -1, GCSet: true, MemoryLimit: 64 << 20}); s != exp {
+		t.Fatalf("unexpected settings: %+v", s)
+	}
+	if got := s.String(); got != "GOMAXPROCS=3 GOGC=off GOMEMLIMIT=67108864" {
+		t.Fatalf("unexpected string: %q", got)
+	}
+	if runtime.GOMAXPROCS(0) != 3 || debug.SetGCPercent(-1) != -1 || debug.SetMemoryLimit(-1) != 64<<20 {
+		t.Fatal("settings are not applied")
+	}
+
+	testEnv(t, Map{"APP_GOMAXPROCS": "0", "APP_GOGC": "-5", "APP_MEMORY_LIMIT": "lots"})
+	var errs []string
+	Log = func(key string, _ error) { errs = append(errs, key) }
+	if s := ApplyRuntime("APP_"); s != (RuntimeSettings{}) || s.String() != "" {
+		t.Fatalf("unexpected settings: %+v", s)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("unexpected errors: %q", errs)
+	}
+
+	testEnv(t, Map{"APP_GOGC": "50"})
+	if s := ApplyRuntime("APP_"); s != (RuntimeSettings{GCPercent: 50, GCSet: true}) || s.String() != "GOGC=50" {
+		t.Fatalf("unexpected settings: %+v", s)
+	}
+	if len(Vars()) != 3 {
+		t.Fatalf("unexpected declarations: %+v", Vars())
+	}
+}
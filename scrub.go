@@ -0,0 +1,82 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// ScrubMode controls what happens to secret variables in the process environment after they are read, see ScrubSecrets.
+type ScrubMode int
+
+const (
+	// ScrubNone keeps secrets in the process environment.
+	ScrubNone ScrubMode = iota
+	// ScrubUnset removes secrets from the process environment, so they are not inherited by child processes.
+	ScrubUnset
+	// ScrubWipe is like ScrubUnset, but also overwrites values in the initial environment of the process,
+	// as exposed by /proc/self/environ. It only has an effect on Linux.
+	ScrubWipe
+)
+
+// ScrubSecrets makes the package remove secret variables (see Var.Secret) from the process environment
+// once they are read. The values are kept in memory and are still returned by getters and Lookup.
+//
+// Variables must be declared as secret before they are read, for example with Declare or with the `secret` struct tag.
+// Copies of values made by the Go runtime when the process started cannot be wiped and are left to the garbage collector.
+var ScrubSecrets = ScrubNone
+
+var (
+	scrubMu  sync.RWMutex
+	scrubbed = make(map[string]string)
+)
+
+// isSecret checks if the variable is declared as secret. Instance-specific variables ("KEY__HOST_web")
+// are secret if the variable itself is. Other names with "__" ("APP__TOKEN") are checked as is.
+func isSecret(key string) bool {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	if v, ok := registry[key]; ok {
+		return v.Secret
+	}
+	for _, sel := range []string{"__HOST_", "__ORDINAL_"} {
+		if i := strings.LastIndex(key, sel); i > 0 {
+			return registry[key[:i]].Secret
+		}
+	}
+	return false
+}
+
+// scrub removes the secret variable from the process environment, if enabled with ScrubSecrets.
+func scrub(key, val string) {
+	mode := ScrubSecrets
+	if mode == ScrubNone || !isSecret(key) {
+		return
+	}
+	scrubMu.Lock()
+	scrubbed[key] = val
+	scrubMu.Unlock()
+	os.Unsetenv(key)
+	if mode == ScrubWipe {
+		wipeEnviron(key)
+	}
+}
+
+// lookupScrubbed returns a value of the variable that was removed from the process environment.
+func lookupScrubbed(key string) (string, bool) {
+	scrubMu.RLock()
+	defer scrubMu.RUnlock()
+	val, ok := scrubbed[key]
+	return val, ok
+}
+
+// scrubbedKeys returns names of variables that were removed from the process environment.
+func scrubbedKeys() []string {
+	scrubMu.RLock()
+	defer scrubMu.RUnlock()
+	keys := make([]string, 0, len(scrubbed))
+	for k := range scrubbed {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,51 @@
+package env
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// wipeEnviron overwrites the value of the variable in the initial environment of the process with zero bytes.
+// This is the memory exposed by /proc/self/environ. Errors are ignored, since wiping is done on a best-effort basis.
+func wipeEnviron(key string) {
+	stat, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return
+	}
+	// fields after the command name, which may contain spaces, start from the 3rd one; env_start is the 50th
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 49 {
+		return
+	}
+	start, err1 := strconv.ParseInt(fields[47], 10, 64) // env_start
+	end, err2 := strconv.ParseInt(fields[48], 10, 64)   // env_end
+	if err1 != nil || err2 != nil || start <= 0 || end <= start {
+		return
+	}
+	f, err := os.OpenFile("/proc/self/mem", os.O_RDWR, 0)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	buf := make([]byte, end-start)
+	if _, err := f.ReadAt(buf, start); err != nil {
+		return
+	}
+	prefix := []byte(key + "=")
+	for off := 0; off < len(buf); {
+		n := bytes.IndexByte(buf[off:], 0)
+		if n < 0 {
+			n = len(buf) - off
+		}
+		if kv := buf[off : off+n]; bytes.HasPrefix(kv, prefix) && len(kv) > len(prefix) {
+			f.WriteAt(make([]byte, len(kv)-len(prefix)), start+int64(off+len(prefix)))
+		}
+		off += n + 1
+	}
+}
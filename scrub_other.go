@@ -0,0 +1,6 @@
+//go:build !linux
+
+package env
+
+// wipeEnviron does nothing, since the initial environment of the process is only accessible on Linux.
+func wipeEnviron(key string) {}
@@ -0,0 +1,28 @@
+package env
+
+import "testing"
+
+func TestIsSecret(t *testing.T) {
+	testEnv(t, nil)
+	Declare(Var{Name: "TOKEN", Secret: true})
+	Declare(Var{Name: "APP__TOKEN", Secret: true})
+	Declare(Var{Name: "APP__NAME"})
+	for _, c := range []struct {
+		key string
+		exp bool
+	}{
+		{"TOKEN", true},
+		{"TOKEN__HOST_web_1", true},
+		{"TOKEN__ORDINAL_2", true},
+		{"APP__TOKEN", true},
+		{"APP__TOKEN__HOST_web", true},
+		{"APP__NAME", false},
+		{"APP__NAME__ORDINAL_0", false},
+		{"TOKEN__OTHER", false},
+		{"UNKNOWN", false},
+	} {
+		if got := isSecret(c.key); got != c.exp {
+			t.Errorf("isSecret(%q) = %v, expected %v", c.key, got, c.exp)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package env
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDestroyed is returned when a destroyed Secret is used.
+var ErrDestroyed = errors.New("secret is destroyed")
+
+// Secret holds a secret value in memory that is protected from leaking: on Linux it is locked into RAM (not swapped),
+// excluded from core dumps and inaccessible outside of Use. On other systems, a regular memory is used.
+//
+// The value is never printed by fmt or encoded as text or JSON, these only produce "(secret)".
+// Destroy must be called to wipe the value once it is no longer needed. Copies of a Secret share the value.
+//
+// Secret implements Decoder, so it can be used as a struct field type with Load. A zero Secret is empty.
+type Secret struct {
+	m *secretMem
+}
+
+type secretMem struct {
+	mu        sync.Mutex
+	buf       []byte // memory region, may be larger than the value
+	n         int
+	locked    bool
+	destroyed bool
+}
+
+// NewSecret copies the value into protected memory and wipes b.
+func NewSecret(b []byte) (Secret, error) {
+	var s Secret
+	if err := s.set(b); err != nil {
+		return Secret{}, err
+	}
+	return s, nil
+}
+
+// LookupSecret gets a secret variable, declaring it as secret (see Var.Secret). It returns false if the variable is not set.
+//
+// The value is found with Lookup, so KEY_FILE and all sources are taken into account. Secrets read from the process
+// environment can additionally be removed from it, see ScrubSecrets.
+func LookupSecret(key string) (Secret, bool) {
+	Declare(Var{Name: key, Type: "string", Secret: true})
+	v, ok := Lookup(key)
+	if !ok {
+		return Secret{}, false
+	}
+	s, err := NewSecret([]byte(v.Value))
+	if err != nil {
+		logError(key, err)
+		return Secret{}, false
+	}
+	return s, true
+}
+
+func (s *Secret) set(b []byte) error {
+	if s.m == nil {
+		s.m = &secretMem{}
+	}
+	m := s.m
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buf != nil {
+		freeLocked(m.buf)
+		m.buf, m.n = nil, 0
+	}
+	buf, locked, err := allocLocked(len(b))
+	if err != nil {
+		return err
+	}
+	copy(buf, b)
+	wipe(b)
+	if err := protect(buf, false); err != nil {
+		freeLocked(buf)
+		return err
+	}
+	m.buf, m.n, m.locked, m.destroyed = buf, len(b), locked, false
+	return nil
+}
+
+// Decode implements Decoder.
+func (s *Secret) Decode(v string) error {
+	return s.set([]byte(v))
+}
+
+// Use calls fnc with the secret value. The slice is only valid during the call and must not be retained or modified.
+func (s Secret) Use(fnc func(b []byte) error) error {
+	m := s.m
+	if m == nil {
+		return fnc(nil)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.destroyed {
+		return ErrDestroyed
+	} else if m.buf == nil {
+		return fnc(nil)
+	}
+	if err := protect(m.buf, true); err != nil {
+		return err
+	}
+	defer protect(m.buf, false)
+	return fnc(m.buf[:m.n:m.n])
+}
+
+// Equal compares the secret value with b in constant time.
+func (s Secret) Equal(b []byte) bool {
+	eq := false
+	s.Use(func(v []byte) error {
+		eq = subtle.ConstantTimeCompare(v, b) == 1
+		return nil
+	})
+	return eq
+}
+
+// Len returns the length of the secret value.
+func (s Secret) Len() int {
+	if s.m == nil {
+		return 0
+	}
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	return s.m.n
+}
+
+// Locked reports if the value is locked into RAM. Locking may fail if the limit of locked memory is too low
+// (see RLIMIT_MEMLOCK), and is not supported on systems other than Linux.
+func (s Secret) Locked() bool {
+	if s.m == nil {
+		return false
+	}
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	return s.m.locked
+}
+
+// Destroy wipes the value and releases the memory. Use returns ErrDestroyed afterwards.
+func (s Secret) Destroy() {
+	m := s.m
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buf != nil {
+		freeLocked(m.buf)
+	}
+	m.buf, m.n, m.locked, m.destroyed = nil, 0, false, true
+}
+
+// String returns "(secret)" instead of the value.
+func (s Secret) String() string {
+	return "(secret)"
+}
+
+// GoString returns "(secret)" instead of the value.
+func (s Secret) GoString() string {
+	return "(secret)"
+}
+
+// Format implements fmt.Formatter, so the value is never printed, regardless of the verb.
+func (s Secret) Format(f fmt.State, _ rune) {
+	f.Write([]byte("(secret)"))
+}
+
+// MarshalText returns "(secret)" instead of the value.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte("(secret)"), nil
+}
+
+// wipe overwrites b with zero bytes.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
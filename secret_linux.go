@@ -0,0 +1,43 @@
+package env
+
+import (
+	"os"
+	"syscall"
+)
+
+const madvDontDump = 0x10 // MADV_DONTDUMP
+
+// allocLocked allocates a memory region outside of the Go heap and tries to lock it into RAM.
+// The region is excluded from core dumps.
+func allocLocked(n int) (b []byte, locked bool, err error) {
+	page := os.Getpagesize()
+	size := (n + page - 1) / page * page
+	if size == 0 {
+		size = page
+	}
+	b, err = syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE|syscall.MAP_ANONYMOUS)
+	if err != nil {
+		return nil, false, err
+	}
+	syscall.Madvise(b, madvDontDump)
+	locked = syscall.Mlock(b) == nil
+	return b, locked, nil
+}
+
+// freeLocked wipes and releases the memory region returned by allocLocked.
+func freeLocked(b []byte) {
+	if protect(b, true) == nil {
+		wipe(b)
+	}
+	syscall.Munlock(b)
+	syscall.Munmap(b)
+}
+
+// protect makes the memory region accessible or inaccessible.
+func protect(b []byte, access bool) error {
+	prot := syscall.PROT_NONE
+	if access {
+		prot = syscall.PROT_READ | syscall.PROT_WRITE
+	}
+	return syscall.Mprotect(b, prot)
+}
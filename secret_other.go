@@ -0,0 +1,18 @@
+//go:build !linux
+
+package env
+
+// allocLocked allocates a regular memory, since locking is only implemented for Linux.
+func allocLocked(n int) (b []byte, locked bool, err error) {
+	return make([]byte, n), false, nil
+}
+
+// freeLocked wipes the memory returned by allocLocked.
+func freeLocked(b []byte) {
+	wipe(b)
+}
+
+// protect does nothing, since memory protection is only implemented for Linux.
+func protect(b []byte, access bool) error {
+	return nil
+}
@@ -0,0 +1,151 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func newTestSecret(t *testing.T, v string) Secret {
+	t.Helper()
+	s, err := NewSecret([]byte(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Destroy)
+	return s
+}
+
+func TestSecretUse(t *testing.T) {
+	b := []byte("hunter2")
+	s, err := NewSecret(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Destroy()
+	if string(b) != "\x00\x00\x00\x00\x00\x00\x00" {
+		t.Fatalf("input is not wiped: %q", b)
+	}
+	var got string
+	if err := s.Use(func(b []byte) error {
+		got = string(b)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got != "hunter2" || s.Len() != 7 {
+		t.Fatalf("unexpected value: %q, %d", got, s.Len())
+	}
+	errTest := errors.New("test")
+	if err := s.Use(func([]byte) error { return errTest }); err != errTest {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSecretEqual(t *testing.T) {
+	s := newTestSecret(t, "abc")
+	for _, c := range []struct {
+		v   string
+		exp bool
+	}{
+		{"abc", true},
+		{"abd", false},
+		{"ab", false},
+		{"", false},
+	} {
+		if got := s.Equal([]byte(c.v)); got != c.exp {
+			t.Errorf("%q: got %v", c.v, got)
+		}
+	}
+	var zero Secret
+	if !zero.Equal(nil) || zero.Len() != 0 || zero.Locked() {
+		t.Fatal("zero secret must be empty")
+	}
+}
+
+func TestSecretDestroy(t *testing.T) {
+	s := newTestSecret(t, "abc")
+	cp := s
+	s.Destroy()
+	called := false
+	if err := cp.Use(func([]byte) error { called = true; return nil }); err != ErrDestroyed || called {
+		t.Fatalf("unexpected result: %v, %v", err, called)
+	}
+	if cp.Equal([]byte("abc")) || cp.Len() != 0 {
+		t.Fatal("destroyed secret must be empty")
+	}
+	s.Destroy()
+	var zero Secret
+	zero.Destroy()
+}
+
+func TestSecretDecode(t *testing.T) {
+	s := newTestSecret(t, "old")
+	cp := s
+	// copies share the value, so decoding into a copy changes both
+	if err := cp.Decode("new"); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal([]byte("new")) || !cp.Equal([]byte("new")) {
+		t.Fatal("copies do not share the value")
+	}
+	// decoding a destroyed secret makes it usable again
+	s.Destroy()
+	if err := cp.Decode("again"); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal([]byte("again")) {
+		t.Fatal("secret is not decoded after being destroyed")
+	}
+	var zero Secret
+	if err := zero.Decode("z"); err != nil {
+		t.Fatal(err)
+	}
+	defer zero.Destroy()
+	if !zero.Equal([]byte("z")) || zero.Len() != 1 {
+		t.Fatal("unexpected value of decoded zero secret")
+	}
+}
+
+func TestSecretMasking(t *testing.T) {
+	s := newTestSecret(t, "hunter2")
+	conf := struct {
+		Name  string
+		Token Secret
+		Key   *Secret
+	}{Name: "app", Token: s, Key: &s}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		if out := fmt.Sprintf(format, conf); strings.Contains(out, "hunter2") || strings.Contains(out, fmt.Sprintf("%x", "hunter2")) {
+			t.Errorf("%s: secret is printed: %s", format, out)
+		}
+		if out := fmt.Sprintf(format, s); out != "(secret)" {
+			t.Errorf("%s: unexpected output: %s", format, out)
+		}
+	}
+	data, err := json.Marshal(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"Name":"app","Token":"(secret)","Key":"(secret)"}`; string(data) != exp {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
+}
+
+func TestLookupSecret(t *testing.T) {
+	testEnv(t, Map{"TOKEN": "t"})
+	s, ok := LookupSecret("TOKEN")
+	if !ok || !s.Equal([]byte("t")) {
+		t.Fatal("unexpected value")
+	}
+	s.Destroy()
+	if _, ok := LookupSecret("MISSING"); ok {
+		t.Fatal("unexpected value")
+	}
+	for _, v := range Vars() {
+		if !v.Secret {
+			t.Errorf("%s is not declared as secret", v.Name)
+		}
+	}
+}
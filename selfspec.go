@@ -0,0 +1,89 @@
+package env
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SpecVar is the name of the variable that makes HandleSpecFlag print the spec. Can be changed by the program.
+var SpecVar = "APP_PRINT_ENV_SPEC"
+
+// SpecFlag is the command line flag that makes HandleSpecFlag print the spec. Can be changed by the program.
+var SpecFlag = "-print-env-spec"
+
+var exit = os.Exit
+
+// CurrentSpec returns a spec of all variables declared or read by the program (see Vars).
+// Variables of types not supported in specs (see Types) are described as strings, and lists of them ("[]int")
+// as "strings". Defaults of secret variables are omitted.
+func CurrentSpec() *Spec {
+	vars := Vars()
+	for i := range vars {
+		if !knownType(vars[i].Type) {
+			vars[i].Type = currentSpecType(vars[i].Type)
+			vars[i].Min, vars[i].Max = nil, nil
+		}
+		if vars[i].Secret {
+			vars[i].Default = ""
+		}
+	}
+	return &Spec{Vars: vars}
+}
+
+// currentSpecType returns a spec type for a variable type that is not supported in specs.
+func currentSpecType(typ string) string {
+	if strings.HasPrefix(typ, "[]") {
+		return "strings"
+	}
+	return "string"
+}
+
+// WriteSpec writes the spec returned by CurrentSpec as JSON. The output can be read back with ParseSpec.
+func WriteSpec(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(CurrentSpec())
+}
+
+// HandleSpecFlag prints the spec of the program variables as JSON to stdout and exits,
+// if the program was started with SpecFlag ("-print-env-spec" or "--print-env-spec") or with SpecVar set to true.
+// Otherwise, it does nothing.
+//
+// Variables are declared when they are read, so it should be called after the configuration is loaded,
+// but before errors from loading it are reported:
+//
+//	err := env.Load(&conf)
+//	env.HandleSpecFlag()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+// This allows deployment tools to validate manifests against the exact binary being deployed.
+func HandleSpecFlag() {
+	if !specRequested(os.Args[1:]) {
+		return
+	}
+	if err := WriteSpec(os.Stdout); err != nil {
+		os.Stderr.WriteString(err.Error() + "\n")
+		exit(1)
+	}
+	exit(0)
+}
+
+func specRequested(args []string) bool {
+	if b, _ := strconv.ParseBool(os.Getenv(SpecVar)); b {
+		return true
+	}
+	for _, a := range args {
+		if a == "--" {
+			break
+		}
+		if a == SpecFlag || a == "-"+SpecFlag {
+			return true
+		}
+	}
+	return false
+}
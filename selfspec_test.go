@@ -0,0 +1,48 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteSpecRoundTrip(t *testing.T) {
+	testEnv(t, nil)
+	one := 1.0
+	Declare(Var{Name: "NAME"})
+	Declare(Var{Name: "PORT", Type: "int", Default: "80", Min: &one})
+	Declare(Var{Name: "IDS", Type: "[]int", Default: "1,2"})
+	Declare(Var{Name: "TIMEOUTS", Type: "[]time.Duration"})
+	Declare(Var{Name: "LIMITS", Type: "map[string]int", Min: &one})
+	Declare(Var{Name: "TOKEN", Type: "string", Default: "x", Secret: true})
+
+	var buf bytes.Buffer
+	if err := WriteSpec(&buf); err != nil {
+		t.Fatal(err)
+	}
+	s, err := ParseSpec(buf.Bytes(), "json")
+	if err != nil {
+		t.Fatalf("cannot read the spec back: %v\n%s", err, buf.String())
+	}
+	exp := map[string]string{
+		"NAME":     "string",
+		"PORT":     "int",
+		"IDS":      "strings",
+		"TIMEOUTS": "strings",
+		"LIMITS":   "string",
+		"TOKEN":    "string",
+	}
+	if len(s.Vars) != len(exp) {
+		t.Fatalf("unexpected vars: %+v", s.Vars)
+	}
+	for _, v := range s.Vars {
+		if v.Type != exp[v.Name] {
+			t.Errorf("%s: unexpected type %q, expected %q", v.Name, v.Type, exp[v.Name])
+		}
+		if v.Name == "TOKEN" && v.Default != "" {
+			t.Errorf("default of a secret is written: %q", v.Default)
+		}
+		if v.Name == "PORT" && v.Min == nil {
+			t.Errorf("min of a supported type is dropped")
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package env
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Writable is a Source that can be changed.
+type Writable interface {
+	Source
+	Set(key, val string) error
+}
+
+// Process is the process environment as a Writable source.
+var Process Writable = osEnv{}
+
+// Set implements Writable.
+func (osEnv) Set(key, val string) error {
+	scrubMu.Lock()
+	delete(scrubbed, key)
+	scrubMu.Unlock()
+	return os.Setenv(key, val)
+}
+
+// Set implements Writable.
+func (m Map) Set(key, val string) error {
+	m[key] = val
+	return nil
+}
+
+var (
+	subMu  sync.RWMutex
+	subs   = make(map[int]func(key string))
+	lastID int
+)
+
+// Subscribe registers a function that is called after a setter changes a value of a variable.
+// The returned function cancels the subscription.
+func Subscribe(fnc func(key string)) (cancel func()) {
+	subMu.Lock()
+	defer subMu.Unlock()
+	lastID++
+	id := lastID
+	subs[id] = fnc
+	return func() {
+		subMu.Lock()
+		delete(subs, id)
+		subMu.Unlock()
+	}
+}
+
+func notify(key string) {
+	subMu.RLock()
+	list := make([]func(string), 0, len(subs))
+	for _, fnc := range subs {
+		list = append(list, fnc)
+	}
+	subMu.RUnlock()
+	for _, fnc := range list {
+		fnc(key)
+	}
+}
+
+// Format formats a value so that a getter of the matching type parses it back to the same value.
+// Supported types are strings, booleans, integers, floats, time.Duration, []string and encoding.TextMarshaler.
+func Format(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Duration:
+		return v.String(), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int8, int16, int32, int64:
+		return fmt.Sprint(v), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case []string:
+		for _, s := range v {
+			if s == "" || strings.Contains(s, ",") || strings.TrimSpace(s) != s {
+				return "", fmt.Errorf("list element %q cannot be represented", s)
+			}
+		}
+		return strings.Join(v, ","), nil
+	case encoding.TextMarshaler:
+		data, err := v.MarshalText()
+		return string(data), err
+	}
+	return "", fmt.Errorf("unsupported type %T", v)
+}
+
+// FormatBytes formats a non-negative size in bytes so that Bytes parses it back to the same value.
+// The largest binary unit that represents the size exactly is used: 65536 is formatted as "64KiB".
+func FormatBytes(n int64) string {
+	for _, u := range []struct {
+		size int64
+		name string
+	}{{1 << 40, "TiB"}, {1 << 30, "GiB"}, {1 << 20, "MiB"}, {1 << 10, "KiB"}} {
+		if n != 0 && n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.name
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// Set formats a value with Format and sets the variable in w (for example, Process or a Map).
+// Subscribers are notified if the value has changed (see Subscribe).
+func Set[T any](w Writable, key string, v T) error {
+	s, err := Format(v)
+	if err != nil {
+		return fmt.Errorf("cannot set %s: %w", key, err)
+	}
+	return setRaw(w, key, s)
+}
+
+func setRaw(w Writable, key, s string) error {
+	if w == nil {
+		return errors.New("env: nil Writable")
+	}
+	if old, _, ok := w.Lookup(key); ok && old == s {
+		return nil
+	}
+	if err := w.Set(key, s); err != nil {
+		return err
+	}
+	notify(key)
+	return nil
+}
+
+// SetString sets a string variable, see Set.
+func SetString(w Writable, key string, v string) error {
+	return setRaw(w, key, v)
+}
+
+// SetBool sets a bool variable, see Set.
+func SetBool(w Writable, key string, v bool) error {
+	return Set(w, key, v)
+}
+
+// SetInt sets an int variable, see Set.
+func SetInt(w Writable, key string, v int) error {
+	return Set(w, key, v)
+}
+
+// SetFloat64 sets a float64 variable, see Set.
+func SetFloat64(w Writable, key string, v float64) error {
+	return Set(w, key, v)
+}
+
+// SetDuration sets a duration variable, see Set.
+func SetDuration(w Writable, key string, v time.Duration) error {
+	return Set(w, key, v)
+}
+
+// SetBytes sets a size in bytes, formatted with FormatBytes. Negative sizes are rejected,
+// since ParseBytes would not read them back. See Set.
+func SetBytes(w Writable, key string, v int64) error {
+	if v < 0 {
+		return fmt.Errorf("cannot set %s: negative byte size %d", key, v)
+	}
+	return setRaw(w, key, FormatBytes(v))
+}
+
+// SetStrings sets a comma-separated list of strings. Elements must not be empty, contain commas
+// or have spaces around them, since Strings would not read them back the same. See Set.
+func SetStrings(w Writable, key string, v []string) error {
+	return Set(w, key, v)
+}
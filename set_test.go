@@ -0,0 +1,115 @@
+package env
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetRoundTrip(t *testing.T) {
+	m := Map{}
+	testEnv(t, m)
+	for _, c := range []struct {
+		name string
+		set  func() error
+		get  func() any
+		exp  any
+	}{
+		{"string", func() error { return SetString(m, "S", " a b ") }, func() any { return String("S", "") }, " a b "},
+		{"bool", func() error { return SetBool(m, "B", true) }, func() any { return Bool("B", false) }, true},
+		{"int", func() error { return SetInt(m, "I", -42) }, func() any { return Int("I", 0) }, -42},
+		{"int64", func() error { return Set(m, "I64", int64(math.MinInt64)) }, func() any { return Int("I64", 0) }, math.MinInt},
+		{"float", func() error { return SetFloat64(m, "F", 0.1) }, func() any { return Float64("F", 0) }, 0.1},
+		{"float large", func() error { return Set(m, "FL", 1e300) }, func() any { return Float64("FL", 0) }, 1e300},
+		{"duration", func() error { return SetDuration(m, "D", 90*time.Minute+time.Millisecond) }, func() any { return Duration("D", 0) }, 90*time.Minute + time.Millisecond},
+		{"negative duration", func() error { return SetDuration(m, "ND", -time.Second) }, func() any { return Duration("ND", 0) }, -time.Second},
+		{"bytes", func() error { return SetBytes(m, "SZ", 3<<30) }, func() any { return Bytes("SZ", 0) }, int64(3 << 30)},
+		{"strings", func() error { return SetStrings(m, "L", []string{"a", "b c", "d"}) }, func() any { return Strings("L", nil) }, []string{"a", "b c", "d"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.set(); err != nil {
+				t.Fatal(err)
+			}
+			if got := c.get(); !reflect.DeepEqual(got, c.exp) {
+				t.Fatalf("got %#v, expected %#v", got, c.exp)
+			}
+		})
+	}
+}
+
+func TestSetBytes(t *testing.T) {
+	for _, v := range []int64{0, 1, 1000, 1024, 65536, 3 << 30, 5 << 40, math.MaxInt64} {
+		m := Map{}
+		if err := SetBytes(m, "A", v); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := ParseBytes(m["A"]); err != nil || n != v {
+			t.Errorf("%d: formatted as %q, parsed as %d (%v)", v, m["A"], n, err)
+		}
+	}
+	for _, v := range []int64{-1000, -1024} {
+		if err := SetBytes(Map{}, "A", v); err == nil {
+			t.Errorf("%d: expected an error", v)
+		}
+	}
+}
+
+func TestSetStringsInvalid(t *testing.T) {
+	for _, v := range [][]string{
+		{"a", ""},
+		{"a,b"},
+		{" a"},
+		{"a "},
+	} {
+		m := Map{"L": "old"}
+		if err := SetStrings(m, "L", v); err == nil {
+			t.Errorf("%q: expected an error", v)
+		}
+		if m["L"] != "old" {
+			t.Errorf("%q: value is changed to %q", v, m["L"])
+		}
+	}
+	if err := Set(Map{}, "A", struct{}{}); err == nil {
+		t.Error("expected an error for unsupported type")
+	}
+	if err := SetInt(nil, "A", 1); err == nil {
+		t.Error("expected an error for nil Writable")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	var got []string
+	cancel := Subscribe(func(key string) {
+		got = append(got, key)
+	})
+	defer cancel()
+
+	m := Map{"A": "1"}
+	if err := SetInt(m, "A", 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("notified on a no-op set: %q", got)
+	}
+	if err := SetInt(m, "A", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetString(m, "B", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetStrings(m, "C", []string{"a,b"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if exp := []string{"A", "B"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got %q, expected %q", got, exp)
+	}
+
+	cancel()
+	if err := SetInt(m, "A", 3); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("notified after cancel: %q", got)
+	}
+}
@@ -0,0 +1,118 @@
+package env
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Setup interactively asks for values of variables and writes them to a dotenv file.
+type Setup struct {
+	In  io.Reader // user input
+	Out io.Writer // prompts and errors
+	// ReadSecret reads a line without echoing it to the terminal.
+	// If not set, secrets are read from In like any other values.
+	ReadSecret func() (string, error)
+	// All makes Setup ask for all variables. By default, only required variables and variables
+	// that are not set in the environment are asked for.
+	All bool
+}
+
+// Run asks for values of variables and writes them to w in dotenv format.
+//
+// Empty input keeps the current value, or selects the default value, which is written as a comment,
+// unless the variable is required.
+// Values are validated with the same parsers as getters use (see Var.Check), and asked again if invalid.
+func (s *Setup) Run(vars []Var, w io.Writer) error {
+	in := bufio.NewReader(s.In)
+	readLine := func() (string, error) {
+		line, err := in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	for _, v := range vars {
+		cur, set := Lookup(v.Name)
+		if set && !s.All && !v.Required {
+			continue
+		}
+		if v.Description != "" {
+			fmt.Fprintf(s.Out, "\n# %s\n", strings.ReplaceAll(v.Description, "\n", "\n# "))
+		}
+		def := v.Default
+		if set {
+			def = cur.Value
+		}
+		var val string
+		for {
+			prompt := v.Name
+			if v.Type != "" && v.Type != "string" {
+				prompt += " (" + v.Type + ")"
+			}
+			switch {
+			case def == "":
+			case !v.Secret:
+				prompt += " [" + def + "]"
+			case set:
+				prompt += " [keep current]"
+			default:
+				// defaults of secrets are not shown
+				prompt += " [use default]"
+			}
+			fmt.Fprint(s.Out, prompt+": ")
+			var err error
+			if v.Secret && s.ReadSecret != nil {
+				val, err = s.ReadSecret()
+				fmt.Fprintln(s.Out)
+			} else {
+				val, err = readLine()
+			}
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			} else if err != nil {
+				return err
+			}
+			val = strings.TrimSpace(val)
+			check := val
+			if check == "" {
+				check = def
+			}
+			if err := v.Check(check); err != nil {
+				fmt.Fprintf(s.Out, "invalid value: %v\n", err)
+				continue
+			}
+			break
+		}
+		if val == "" && (set || v.Required) {
+			// required variables must be set in the file, even to the default value
+			val = def
+		}
+		if err := writeSetupVar(w, v, val, def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSetupVar(w io.Writer, v Var, val, def string) error {
+	var sb strings.Builder
+	if v.Description != "" {
+		for _, line := range strings.Split(strings.TrimSpace(v.Description), "\n") {
+			sb.WriteString("# " + line + "\n")
+		}
+	}
+	switch {
+	case val != "":
+		sb.WriteString(v.Name + "=" + quoteDotenv(val) + "\n")
+	case def != "" && !v.Secret:
+		sb.WriteString("# " + v.Name + "=" + quoteDotenv(def) + "\n")
+	default:
+		sb.WriteString("# " + v.Name + "=\n")
+	}
+	sb.WriteString("\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,45 @@
+package env
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSetupDefaults(t *testing.T) {
+	testEnv(t, Map{"SET": "cur"})
+	vars := []Var{
+		{Name: "OPTIONAL", Default: "a"},
+		{Name: "REQUIRED", Default: "b", Required: true},
+		{Name: "SET", Default: "c", Required: true},
+		{Name: "PORT", Type: "int", Default: "80", Required: true},
+		{Name: "NAME"},
+	}
+	s := &Setup{In: strings.NewReader("\n\n\nx\n8080\n\n"), Out: io.Discard}
+	var out strings.Builder
+	if err := s.Run(vars, &out); err != nil {
+		t.Fatal(err)
+	}
+	exp := "# OPTIONAL=a\n\nREQUIRED=b\n\nSET=cur\n\nPORT=8080\n\n# NAME=\n\n"
+	if out.String() != exp {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", out.String(), exp)
+	}
+}
+
+func TestSetupSecretPrompts(t *testing.T) {
+	testEnv(t, Map{"CUR": "c"})
+	vars := []Var{
+		{Name: "CUR", Secret: true, Default: "d"},
+		{Name: "DEF", Secret: true, Default: "d"},
+		{Name: "NONE", Secret: true},
+	}
+	var prompts strings.Builder
+	s := &Setup{In: strings.NewReader("\n\n\n"), Out: &prompts, All: true}
+	if err := s.Run(vars, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	exp := "CUR [keep current]: DEF [use default]: NONE: "
+	if prompts.String() != exp {
+		t.Fatalf("unexpected prompts: %q", prompts.String())
+	}
+}
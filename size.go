@@ -0,0 +1,83 @@
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxArgStrlen is the maximal size of a single argument or environment string on Linux (MAX_ARG_STRLEN),
+// including the terminating zero byte.
+const MaxArgStrlen = 32 * 4096
+
+// bigEntry is a size of the environment string that is worth moving to a file.
+const bigEntry = 4096
+
+// SizeEntry is a size of a single environment string.
+type SizeEntry struct {
+	Key  string
+	Size int // size of "KEY=value" including the terminating zero byte
+}
+
+// SizeReport describes the size of the environment passed to execve.
+type SizeReport struct {
+	Total   int         // total size of the environment and arguments, including the pointer arrays
+	Limit   int         // limit for the total size (ARG_MAX); zero if unknown
+	Entries []SizeEntry // environment strings, largest first
+}
+
+// CheckSize computes the size of the environment (in "KEY=value" form, see os.Environ) and arguments
+// the same way Linux does it for execve, and compares it with the limits of the current process.
+func CheckSize(environ []string, args ...string) *SizeReport {
+	r := &SizeReport{Limit: argMax()}
+	ptr := 8 // size of a pointer in argv and envp
+	for _, a := range args {
+		r.Total += len(a) + 1 + ptr
+	}
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		e := SizeEntry{Key: k, Size: len(kv) + 1}
+		r.Total += e.Size + ptr
+		r.Entries = append(r.Entries, e)
+	}
+	sort.SliceStable(r.Entries, func(i, j int) bool { return r.Entries[i].Size > r.Entries[j].Size })
+	return r
+}
+
+// Problems returns descriptions of environment strings that exceed limits or are large enough to be worth
+// moving to files, and of the total size exceeding the limit. Each problem includes a suggestion.
+func (r *SizeReport) Problems() []string {
+	var out []string
+	for _, e := range r.Entries {
+		switch {
+		case e.Size > MaxArgStrlen:
+			out = append(out, fmt.Sprintf("%s: %d bytes exceeds the limit of %d bytes for a single variable (E2BIG); write the value to a file and set %s_FILE to its path",
+				e.Key, e.Size, MaxArgStrlen, e.Key))
+		case e.Size > bigEntry:
+			out = append(out, fmt.Sprintf("%s: %d bytes is large; consider writing the value to a file and setting %s_FILE to its path",
+				e.Key, e.Size, e.Key))
+		}
+	}
+	if r.Limit > 0 && r.Total > r.Limit {
+		var top []string
+		for i := 0; i < len(r.Entries) && i < 3; i++ {
+			top = append(top, r.Entries[i].Key)
+		}
+		out = append(out, fmt.Sprintf("total size of %d bytes exceeds the limit of %d bytes (E2BIG); largest variables: %s",
+			r.Total, r.Limit, strings.Join(top, ", ")))
+	}
+	return out
+}
+
+// Err returns an error if any environment string or the total size exceeds the limits.
+func (r *SizeReport) Err() error {
+	for _, e := range r.Entries {
+		if e.Size > MaxArgStrlen {
+			return fmt.Errorf("variable %s is too large: %d > %d bytes", e.Key, e.Size, MaxArgStrlen)
+		}
+	}
+	if r.Limit > 0 && r.Total > r.Limit {
+		return fmt.Errorf("environment is too large: %d > %d bytes", r.Total, r.Limit)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package env
+
+import "syscall"
+
+// argMax returns the limit for the total size of arguments and environment, computed the same way as Linux does it:
+// a quarter of the stack size limit, but no more than 6MiB and no less than 128KiB.
+func argMax() int {
+	const (
+		minLimit = 32 * 4096
+		maxLimit = 8 << 20 / 4 * 3
+	)
+	var lim syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_STACK, &lim); err != nil {
+		return minLimit
+	}
+	n := uint64(maxLimit)
+	if lim.Cur/4 < n {
+		n = lim.Cur / 4
+	}
+	if n < minLimit {
+		n = minLimit
+	}
+	return int(n)
+}
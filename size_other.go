@@ -0,0 +1,8 @@
+//go:build !linux
+
+package env
+
+// argMax returns zero, since the limit is only known for Linux.
+func argMax() int {
+	return 0
+}
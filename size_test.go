@@ -0,0 +1,66 @@
+package env
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCheckSize(t *testing.T) {
+	big := "BIG=" + strings.Repeat("x", 5000)
+	r := CheckSize([]string{"A=1", big, "HUGE=" + strings.Repeat("x", MaxArgStrlen)}, "prog", "-v")
+	exp := (len("prog") + 1 + 8) + (len("-v") + 1 + 8) + (len("A=1") + 1 + 8) + (len(big) + 1 + 8) + (5 + MaxArgStrlen + 1 + 8)
+	if r.Total != exp {
+		t.Fatalf("unexpected total: %d, expected %d", r.Total, exp)
+	}
+	var keys []string
+	for _, e := range r.Entries {
+		keys = append(keys, e.Key)
+	}
+	if got := strings.Join(keys, ","); got != "HUGE,BIG,A" {
+		t.Fatalf("entries are not sorted by size: %s", got)
+	}
+	if r.Entries[2].Size != len("A=1")+1 {
+		t.Fatalf("unexpected entry size: %+v", r.Entries[2])
+	}
+	probs := r.Problems()
+	if len(probs) < 2 || !strings.Contains(probs[0], "HUGE") || !strings.Contains(probs[0], "E2BIG") ||
+		!strings.Contains(probs[1], "BIG") || !strings.Contains(probs[1], "BIG_FILE") {
+		t.Fatalf("unexpected problems: %q", probs)
+	}
+	if err := r.Err(); err == nil || !strings.Contains(err.Error(), "HUGE") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSizeReportLimit(t *testing.T) {
+	r := CheckSize([]string{"A=1", "B=22"})
+	if len(r.Problems()) != 0 || r.Err() != nil {
+		t.Fatalf("unexpected problems: %q, %v", r.Problems(), r.Err())
+	}
+	r.Limit = r.Total - 1
+	probs := r.Problems()
+	if len(probs) != 1 || !strings.Contains(probs[0], "largest variables: B, A") {
+		t.Fatalf("unexpected problems: %q", probs)
+	}
+	if r.Err() == nil {
+		t.Fatal("expected an error")
+	}
+	r.Limit = 0
+	if r.Err() != nil {
+		t.Fatal("unknown limit must not be exceeded")
+	}
+}
+
+func TestArgMax(t *testing.T) {
+	n := argMax()
+	if runtime.GOOS != "linux" {
+		if n != 0 {
+			t.Fatalf("unexpected limit: %d", n)
+		}
+		return
+	}
+	if n < 32*4096 || n > 6<<20 {
+		t.Fatalf("limit is out of range: %d", n)
+	}
+}
@@ -0,0 +1,124 @@
+package env
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Source is a set of variables that are used in addition to the process environment.
+type Source interface {
+	// Lookup returns a raw value of the variable and a place it was defined at (for example, a file name).
+	Lookup(key string) (val, from string, ok bool)
+	// Keys returns names of all variables defined in the source.
+	Keys() []string
+}
+
+var (
+	srcMu    sync.RWMutex
+	noOSEnv  bool // process environment is not used, see Isolate
+	sources  []Source
+	defaults []Source
+)
+
+// Map is a Source with variables from a map.
+type Map map[string]string
+
+// Lookup implements Source.
+func (m Map) Lookup(key string) (val, from string, ok bool) {
+	val, ok = m[key]
+	return val, "map", ok
+}
+
+// Keys implements Source.
+func (m Map) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Use adds a source of variables for all getters.
+//
+// Process environment always takes precedence over sources, and sources added first take precedence over ones added later.
+func Use(s Source) {
+	srcMu.Lock()
+	defer srcMu.Unlock()
+	sources = append(sources, s)
+}
+
+// UseDefaults adds a source of default values, for example the one embedded into the binary (see ReadFS).
+//
+// Defaults have the lowest precedence: they are used only if neither the process environment nor any source added with Use
+// defines the variable. Defaults are also shown instead of the in-code defaults in docs (see WriteDocs).
+func UseDefaults(s Source) {
+	srcMu.Lock()
+	defer srcMu.Unlock()
+	defaults = append(defaults, s)
+}
+
+// lookupDefault finds a non-empty variable in one of the sources added with UseDefaults.
+func lookupDefault(key string) (val, from string, ok bool) {
+	srcMu.RLock()
+	list := defaults
+	srcMu.RUnlock()
+	val, _, from, ok = lookupIn(list, key)
+	return val, from, ok
+}
+
+// environment returns the process environment and sources added with Use, without defaults.
+// It must be called with srcMu held.
+func environment() []Source {
+	list := make([]Source, 0, 1+len(sources)+len(defaults))
+	if !noOSEnv {
+		list = append(list, osEnv{})
+	}
+	return append(list, sources...)
+}
+
+// lookupIn finds the key in the first source that has it. Normalized names (see KeyNormalizer)
+// are only matched if no source has the exact name.
+func lookupIn(list []Source, key string) (val, raw, from string, ok bool) {
+	for _, src := range list {
+		if s, from, ok := src.Lookup(key); ok && s != "" {
+			return s, key, from, true
+		}
+	}
+	for _, src := range list {
+		if s, raw, from, ok := lookupNormalized(src, key); ok {
+			return s, raw, from, true
+		}
+	}
+	return "", "", "", false
+}
+
+// osEnv is a Source for the process environment.
+type osEnv struct{}
+
+func (osEnv) Lookup(key string) (val, from string, ok bool) {
+	if val, ok = os.LookupEnv(key); ok {
+		scrub(key, val)
+		return val, "env", true
+	}
+	val, ok = lookupScrubbed(key)
+	return val, "env", ok
+}
+
+func (osEnv) Keys() []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(env))
+	for _, kv := range env {
+		if k, _, _ := strings.Cut(kv, "="); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range scrubbedKeys() {
+		if _, ok := os.LookupEnv(k); !ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,130 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dennwc/env/internal/toml"
+	"github.com/dennwc/env/internal/yaml"
+)
+
+// Spec is a declarative description of variables used by a program.
+type Spec struct {
+	Package string `json:"package,omitempty"` // Go package name for generated code
+	Struct  string `json:"struct,omitempty"`  // Go type name for generated code
+	Vars    []Var  `json:"vars"`
+}
+
+// Types lists variable types supported in specs.
+var Types = []string{"string", "bool", "int", "float64", "duration", "bytes", "strings", "milli", "quantity"}
+
+// ReadSpec reads a spec from a file. The format is selected by the file extension: ".json", ".yaml", ".yml" or ".toml".
+func ReadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseSpec(data, strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ParseSpec parses a spec in a given format: "json", "yaml" or "toml".
+//
+// Defaults and enum values may be written as any scalars, they are converted to strings.
+func ParseSpec(data []byte, format string) (*Spec, error) {
+	var v any
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+	case "yaml", "yml":
+		docs, err := yaml.Unmarshal(data)
+		if err != nil {
+			return nil, err
+		} else if len(docs) != 1 {
+			return nil, fmt.Errorf("expected a single YAML document, got %d", len(docs))
+		}
+		v = docs[0]
+	case "toml":
+		m, err := toml.Unmarshal(data)
+		if err != nil {
+			return nil, err
+		}
+		v = m
+	default:
+		return nil, fmt.Errorf("unsupported spec format: %q", format)
+	}
+	if m, ok := v.(map[string]any); ok {
+		vars, _ := m["vars"].([]any)
+		for _, d := range vars {
+			d, _ := d.(map[string]any)
+			if def, ok := d["default"]; ok {
+				d["default"] = scalarString(def)
+			}
+			if enum, ok := d["enum"].([]any); ok {
+				for i := range enum {
+					enum[i] = scalarString(enum[i])
+				}
+			}
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var s Spec
+	if err := dec.Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, s.Validate()
+}
+
+func scalarString(v any) any {
+	switch v := v.(type) {
+	case nil, string, map[string]any, []any:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// Validate checks that all variables in the spec have names and supported types,
+// and that min and max constraints are only set for numeric types.
+func (s *Spec) Validate() error {
+	seen := make(map[string]bool)
+	for i, v := range s.Vars {
+		if v.Name == "" {
+			return fmt.Errorf("variable %d has no name", i)
+		} else if seen[v.Name] {
+			return fmt.Errorf("variable %s is declared twice", v.Name)
+		}
+		seen[v.Name] = true
+		if !knownType(v.Type) {
+			return fmt.Errorf("variable %s has unsupported type %q", v.Name, v.Type)
+		}
+		switch v.Type {
+		case "string", "bool", "strings":
+			if v.Min != nil || v.Max != nil {
+				return fmt.Errorf("variable %s of type %s cannot have min or max", v.Name, v.Type)
+			}
+		}
+	}
+	return nil
+}
+
+func knownType(typ string) bool {
+	_, ok := parsers[typ]
+	return ok
+}
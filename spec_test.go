@@ -0,0 +1,49 @@
+package env
+
+import "testing"
+
+func TestParseSpecDefaults(t *testing.T) {
+	cases := []struct {
+		format, data string
+		exp          []string
+	}{
+		{"json", `{"vars":[{"name":"N","type":"int","default":1000000},{"name":"F","type":"float64","default":0.25},{"name":"B","type":"bool","default":true}]}`, []string{"1000000", "0.25", "true"}},
+		{"yaml", "vars:\n  - name: N\n    type: int\n    default: 1000000\n  - name: F\n    type: float64\n    default: 1e-7\n  - name: Z\n    type: string\n    default: 010\n", []string{"1000000", "0.0000001", "010"}},
+		{"toml", "[[vars]]\nname = \"N\"\ntype = \"int\"\ndefault = 1_000_000\n", []string{"1000000"}},
+	}
+	for _, c := range cases {
+		s, err := ParseSpec([]byte(c.data), c.format)
+		if err != nil {
+			t.Fatalf("%s: %v", c.format, err)
+		}
+		for i, exp := range c.exp {
+			if got := s.Vars[i].Default; got != exp {
+				t.Errorf("%s: %s: expected default %q, got %q", c.format, s.Vars[i].Name, exp, got)
+			}
+		}
+	}
+}
+
+func TestSpecValidateBounds(t *testing.T) {
+	for _, typ := range []string{"string", "bool", "strings"} {
+		s, err := ParseSpec([]byte(`{"vars":[{"name":"A","type":"`+typ+`","min":1}]}`), "json")
+		if err == nil {
+			t.Errorf("%s: expected an error, got %+v", typ, s)
+		}
+	}
+	if _, err := ParseSpec([]byte(`{"vars":[{"name":"A","type":"duration","min":1,"max":2}]}`), "json"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckEnumList(t *testing.T) {
+	v := Var{Name: "A", Type: "strings", Enum: []string{"x", "y"}}
+	for _, s := range []string{"x", "x,y", "y, x"} {
+		if err := v.Check(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	if err := v.Check("x,z"); err == nil {
+		t.Error("expected an error")
+	}
+}
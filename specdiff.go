@@ -0,0 +1,198 @@
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SpecChange is a difference between two versions of a spec, see CompareSpecs.
+type SpecChange struct {
+	Name     string // variable name
+	Change   string // description of the change, for example "removed" or "type changed from int to string"
+	Breaking bool   // the change may break existing deployments
+}
+
+func (c SpecChange) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	return kind + ": " + c.Name + ": " + c.Change
+}
+
+// BreakingChanges returns only breaking changes from the list.
+func BreakingChanges(changes []SpecChange) []SpecChange {
+	var out []SpecChange
+	for _, c := range changes {
+		if c.Breaking {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// CompareSpecs compares an old and a new version of a spec and returns differences between them, sorted by variable name.
+//
+// The following changes are considered breaking, since existing deployments may stop working or silently change behavior:
+//
+//   - a variable is removed or renamed (a removed variable is reported as renamed if an added one has the same type and description)
+//   - a required variable without a default is added, or an existing variable becomes required
+//   - a type changes, unless the new type accepts all old values (int to float64, anything to string)
+//   - a default changes
+//   - enum values are removed, or a constraint becomes stricter
+//
+// Other changes, like added optional variables or relaxed constraints, are compatible. Changes in descriptions are ignored.
+func CompareSpecs(old, cur *Spec) []SpecChange {
+	oldVars := make(map[string]Var, len(old.Vars))
+	for _, v := range old.Vars {
+		oldVars[v.Name] = v
+	}
+	curVars := make(map[string]Var, len(cur.Vars))
+	for _, v := range cur.Vars {
+		curVars[v.Name] = v
+	}
+	var (
+		out     []SpecChange
+		added   []Var
+		removed []Var
+	)
+	for _, v := range cur.Vars {
+		o, ok := oldVars[v.Name]
+		if !ok {
+			added = append(added, v)
+			continue
+		}
+		out = append(out, compareVars(o, v)...)
+	}
+	for _, v := range old.Vars {
+		if _, ok := curVars[v.Name]; !ok {
+			removed = append(removed, v)
+		}
+	}
+	renamed := make(map[string]bool)
+	for _, o := range removed {
+		var to string
+		for _, v := range added {
+			if !renamed[v.Name] && o.Description != "" && v.Description == o.Description && specType(v) == specType(o) {
+				to = v.Name
+				break
+			}
+		}
+		if to == "" {
+			out = append(out, SpecChange{Name: o.Name, Change: "removed", Breaking: true})
+			continue
+		}
+		renamed[to] = true
+		out = append(out, SpecChange{Name: o.Name, Change: "renamed to " + to, Breaking: true})
+	}
+	for _, v := range added {
+		if renamed[v.Name] {
+			continue
+		}
+		if v.Required && v.Default == "" {
+			out = append(out, SpecChange{Name: v.Name, Change: "added as required", Breaking: true})
+		} else {
+			out = append(out, SpecChange{Name: v.Name, Change: "added"})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// specType returns the type of the variable, as interpreted by Var.Check.
+func specType(v Var) string {
+	if v.Type == "" {
+		return "string"
+	}
+	return v.Type
+}
+
+func compareVars(o, v Var) []SpecChange {
+	var out []SpecChange
+	add := func(breaking bool, format string, args ...any) {
+		out = append(out, SpecChange{Name: v.Name, Change: fmt.Sprintf(format, args...), Breaking: breaking})
+	}
+	if ot, vt := specType(o), specType(v); ot != vt {
+		add(!(vt == "string" || (ot == "int" && vt == "float64")), "type changed from %s to %s", ot, vt)
+	}
+	if o.Default != v.Default {
+		switch {
+		case o.Secret || v.Secret:
+			add(true, "default changed")
+		case o.Default == "":
+			add(true, "default %q added", v.Default)
+		case v.Default == "":
+			add(true, "default %q removed", o.Default)
+		default:
+			add(true, "default changed from %q to %q", o.Default, v.Default)
+		}
+	}
+	if o.Required != v.Required {
+		if v.Required {
+			add(v.Default == "", "became required")
+		} else {
+			add(false, "became optional")
+		}
+	}
+	if o.Secret != v.Secret {
+		if v.Secret {
+			add(false, "became secret")
+		} else {
+			add(false, "is no longer secret")
+		}
+	}
+	if len(v.Enum) != 0 {
+		if len(o.Enum) == 0 {
+			add(true, "restricted to %s", strings.Join(v.Enum, ", "))
+		} else if rm := missing(o.Enum, v.Enum); len(rm) != 0 {
+			add(true, "enum values removed: %s", strings.Join(rm, ", "))
+		}
+		if nw := missing(v.Enum, o.Enum); len(o.Enum) != 0 && len(nw) != 0 {
+			add(false, "enum values added: %s", strings.Join(nw, ", "))
+		}
+	} else if len(o.Enum) != 0 {
+		add(false, "enum restriction removed")
+	}
+	compareBound(add, "min", o.Min, v.Min, func(a, b float64) bool { return b > a })
+	compareBound(add, "max", o.Max, v.Max, func(a, b float64) bool { return b < a })
+	return out
+}
+
+// compareBound reports a change of a numeric constraint. The stricter function reports if b is stricter than a.
+func compareBound(add func(breaking bool, format string, args ...any), name string, a, b *float64, stricter func(a, b float64) bool) {
+	switch {
+	case a == nil && b == nil:
+	case a == nil:
+		add(true, "%s %s added", name, formatFloat(*b))
+	case b == nil:
+		add(false, "%s %s removed", name, formatFloat(*a))
+	case *a != *b:
+		add(stricter(*a, *b), "%s changed from %s to %s", name, formatFloat(*a), formatFloat(*b))
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// missing returns values from a that are not in b.
+func missing(a, b []string) []string {
+	var out []string
+	for _, s := range a {
+		found := false
+		for _, s2 := range b {
+			if s == s2 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareSpecs(t *testing.T) {
+	one, two, ten := 1.0, 2.0, 10.0
+	for _, c := range []struct {
+		name     string
+		old, cur []Var
+		exp      []SpecChange
+	}{
+		{
+			name: "no changes",
+			old:  []Var{{Name: "A", Type: "int", Description: "a"}},
+			cur:  []Var{{Name: "A", Type: "int", Description: "changed"}},
+		},
+		{
+			name: "removed",
+			old:  []Var{{Name: "A", Type: "int"}},
+			exp:  []SpecChange{{Name: "A", Change: "removed", Breaking: true}},
+		},
+		{
+			name: "renamed",
+			old:  []Var{{Name: "A", Type: "int", Description: "port"}},
+			cur:  []Var{{Name: "B", Type: "int", Description: "port"}},
+			exp:  []SpecChange{{Name: "A", Change: "renamed to B", Breaking: true}},
+		},
+		{
+			name: "not renamed with another type",
+			old:  []Var{{Name: "A", Type: "int", Description: "port"}},
+			cur:  []Var{{Name: "B", Type: "string", Description: "port"}},
+			exp: []SpecChange{
+				{Name: "A", Change: "removed", Breaking: true},
+				{Name: "B", Change: "added"},
+			},
+		},
+		{
+			name: "not renamed without description",
+			old:  []Var{{Name: "A", Type: "int"}},
+			cur:  []Var{{Name: "B", Type: "int"}},
+			exp: []SpecChange{
+				{Name: "A", Change: "removed", Breaking: true},
+				{Name: "B", Change: "added"},
+			},
+		},
+		{
+			name: "added",
+			cur: []Var{
+				{Name: "A"},
+				{Name: "B", Required: true},
+				{Name: "C", Required: true, Default: "x"},
+			},
+			exp: []SpecChange{
+				{Name: "A", Change: "added"},
+				{Name: "B", Change: "added as required", Breaking: true},
+				{Name: "C", Change: "added"},
+			},
+		},
+		{
+			name: "int to float64",
+			old:  []Var{{Name: "A", Type: "int"}},
+			cur:  []Var{{Name: "A", Type: "float64"}},
+			exp:  []SpecChange{{Name: "A", Change: "type changed from int to float64"}},
+		},
+		{
+			name: "to string",
+			old:  []Var{{Name: "A", Type: "duration"}, {Name: "B", Type: "int"}},
+			cur:  []Var{{Name: "A", Type: "string"}, {Name: "B"}},
+			exp: []SpecChange{
+				{Name: "A", Change: "type changed from duration to string"},
+				{Name: "B", Change: "type changed from int to string"},
+			},
+		},
+		{
+			name: "float64 to int",
+			old:  []Var{{Name: "A", Type: "float64"}},
+			cur:  []Var{{Name: "A", Type: "int"}},
+			exp:  []SpecChange{{Name: "A", Change: "type changed from float64 to int", Breaking: true}},
+		},
+		{
+			name: "became required",
+			old:  []Var{{Name: "A"}, {Name: "B", Default: "x"}, {Name: "C", Required: true}},
+			cur:  []Var{{Name: "A", Required: true}, {Name: "B", Default: "x", Required: true}, {Name: "C"}},
+			exp: []SpecChange{
+				{Name: "A", Change: "became required", Breaking: true},
+				{Name: "B", Change: "became required"},
+				{Name: "C", Change: "became optional"},
+			},
+		},
+		{
+			name: "defaults",
+			old:  []Var{{Name: "A", Default: "1"}, {Name: "B"}, {Name: "C", Default: "1"}, {Name: "D", Default: "1", Secret: true}},
+			cur:  []Var{{Name: "A", Default: "2"}, {Name: "B", Default: "1"}, {Name: "C"}, {Name: "D", Default: "2", Secret: true}},
+			exp: []SpecChange{
+				{Name: "A", Change: `default changed from "1" to "2"`, Breaking: true},
+				{Name: "B", Change: `default "1" added`, Breaking: true},
+				{Name: "C", Change: `default "1" removed`, Breaking: true},
+				{Name: "D", Change: "default changed", Breaking: true},
+			},
+		},
+		{
+			name: "enum",
+			old:  []Var{{Name: "A", Enum: []string{"a", "b"}}, {Name: "B", Enum: []string{"a"}}, {Name: "C"}, {Name: "D", Enum: []string{"a"}}},
+			cur:  []Var{{Name: "A", Enum: []string{"a"}}, {Name: "B", Enum: []string{"a", "b"}}, {Name: "C", Enum: []string{"a"}}, {Name: "D"}},
+			exp: []SpecChange{
+				{Name: "A", Change: "enum values removed: b", Breaking: true},
+				{Name: "B", Change: "enum values added: b"},
+				{Name: "C", Change: "restricted to a", Breaking: true},
+				{Name: "D", Change: "enum restriction removed"},
+			},
+		},
+		{
+			name: "enum values replaced",
+			old:  []Var{{Name: "A", Enum: []string{"a", "b"}}},
+			cur:  []Var{{Name: "A", Enum: []string{"a", "c"}}},
+			exp: []SpecChange{
+				{Name: "A", Change: "enum values removed: b", Breaking: true},
+				{Name: "A", Change: "enum values added: c"},
+			},
+		},
+		{
+			name: "bounds",
+			old: []Var{
+				{Name: "A", Type: "int", Min: &one},
+				{Name: "B", Type: "int", Min: &two},
+				{Name: "C", Type: "int", Max: &two},
+				{Name: "D", Type: "int", Max: &ten},
+				{Name: "E", Type: "int"},
+				{Name: "F", Type: "int", Max: &ten},
+			},
+			cur: []Var{
+				{Name: "A", Type: "int", Min: &two},
+				{Name: "B", Type: "int", Min: &one},
+				{Name: "C", Type: "int", Max: &ten},
+				{Name: "D", Type: "int", Max: &two},
+				{Name: "E", Type: "int", Min: &one},
+				{Name: "F", Type: "int"},
+			},
+			exp: []SpecChange{
+				{Name: "A", Change: "min changed from 1 to 2", Breaking: true},
+				{Name: "B", Change: "min changed from 2 to 1"},
+				{Name: "C", Change: "max changed from 2 to 10"},
+				{Name: "D", Change: "max changed from 10 to 2", Breaking: true},
+				{Name: "E", Change: "min 1 added", Breaking: true},
+				{Name: "F", Change: "max 10 removed"},
+			},
+		},
+		{
+			name: "secret",
+			old:  []Var{{Name: "A"}, {Name: "B", Secret: true}},
+			cur:  []Var{{Name: "A", Secret: true}, {Name: "B"}},
+			exp: []SpecChange{
+				{Name: "A", Change: "became secret"},
+				{Name: "B", Change: "is no longer secret"},
+			},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := CompareSpecs(&Spec{Vars: c.old}, &Spec{Vars: c.cur})
+			if !reflect.DeepEqual(got, c.exp) {
+				t.Fatalf("unexpected changes:\n%v\nexpected:\n%v", got, c.exp)
+			}
+		})
+	}
+}
+
+func TestBreakingChanges(t *testing.T) {
+	changes := []SpecChange{{Name: "A", Breaking: true}, {Name: "B"}, {Name: "C", Breaking: true}}
+	got := BreakingChanges(changes)
+	if len(got) != 2 || got[0].Name != "A" || got[1].Name != "C" {
+		t.Fatalf("unexpected changes: %v", got)
+	}
+	if s := changes[0].String(); s != "breaking: A: " {
+		t.Fatalf("unexpected string: %q", s)
+	}
+}
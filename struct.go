@@ -0,0 +1,323 @@
+package env
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Defaulter is implemented by structs that set their default values before being loaded.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// Validator is implemented by structs that check their values after being loaded.
+type Validator interface {
+	Validate() error
+}
+
+// Decoder is implemented by field types that parse the variable value themselves.
+type Decoder interface {
+	Decode(s string) error
+}
+
+// FieldError is returned by Load when a field cannot be loaded or validated.
+type FieldError struct {
+	Path string // path of the field in the struct, for example "Config.DB.Port"
+	Key  string // variable name; empty for errors returned by Validate
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Key == "" {
+		return e.Path + ": " + e.Err.Error()
+	}
+	return e.Path + " (" + e.Key + "): " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	decoderType     = reflect.TypeOf((*Decoder)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	secretType      = reflect.TypeOf(Secret{})
+)
+
+// Load reads variables into a struct pointed by dst.
+//
+// Each field is read from a variable named by the `env:"KEY"` tag, or by the field name converted
+// to upper snake case ("HTTPPort" is read from "HTTP_PORT"). Fields tagged with `env:"-"` are skipped.
+// Nested structs are loaded with a prefix: fields of the struct in a field tagged `env:"DB"` are read
+// from "DB_*" variables. Embedded structs without the tag are flattened: their fields are read without an additional prefix.
+// Other supported tags are:
+//
+//	default:"value"       - used if the variable is not set
+//	desc:"description"    - description for docs
+//	secret:"true"         - variable is a secret
+//	required:"true"       - the variable must be set, unless there is a default
+//
+// Supported field types are strings, booleans, integers, floats, time.Duration, slices of them (comma-separated),
+// and types implementing Decoder or encoding.TextUnmarshaler.
+//
+// Structs implementing Defaulter get SetDefaults called before their fields are read,
+// and the ones implementing Validator get Validate called after all their fields and nested structs are loaded.
+//
+// Loaded variables are declared in the registry (see Vars). All errors are returned together, each as a *FieldError.
+//
+// Tags of other popular libraries are understood when loading with a corresponding Dialect.
+// Fields of interface types registered with RegisterUnion are loaded as tagged unions.
+func Load(dst any) error {
+	return Native.Load("", dst)
+}
+
+// LoadPrefix is like Load, but adds a prefix to names of all variables.
+func LoadPrefix(prefix string, dst any) error {
+	return Native.Load(prefix, dst)
+}
+
+// Load is like LoadPrefix, but understands struct tags of a given dialect.
+func (d Dialect) Load(prefix string, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("env: expected a pointer to a struct, got %T", dst)
+	}
+	l := &structLoader{dialect: d}
+	l.loadStruct(rv.Elem(), rv.Elem().Type().Name(), prefix)
+	return errors.Join(l.errs...)
+}
+
+type structLoader struct {
+	dialect Dialect
+	group   string // group of declared variables, see Var.Group
+	errs    []error
+}
+
+func (l *structLoader) fail(path, key string, err error) {
+	if key != "" {
+		recordError(key, err)
+	}
+	l.errs = append(l.errs, &FieldError{Path: path, Key: key, Err: err})
+}
+
+func (l *structLoader) loadStruct(rv reflect.Value, path, prefix string) {
+	// structs of unexported embedded types cannot be accessed, but their methods are promoted to the parent
+	var hooks any
+	if rv.Addr().CanInterface() {
+		hooks = rv.Addr().Interface()
+	}
+	if d, ok := hooks.(Defaulter); ok {
+		d.SetDefaults()
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !loadable(f) {
+			continue
+		}
+		nested := isNested(f.Type)
+		tags, ok := l.dialect.parseTags(f, nested)
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		fpath := path + "." + f.Name
+		if u := lookupUnion(f.Type); u != nil {
+			l.loadUnion(fv, fpath, prefix+tags.key, tags, u)
+			continue
+		}
+		if nested {
+			if f.Type.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(f.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			l.loadStruct(fv, fpath, prefix+tags.prefix)
+			continue
+		}
+		l.loadField(fv, fpath, prefix+tags.key, tags)
+	}
+	if v, ok := hooks.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			l.fail(path, "", err)
+		}
+	}
+}
+
+// loadable checks if the field can be set. Fields of embedded structs are promoted even if the struct type is unexported.
+func loadable(f reflect.StructField) bool {
+	return f.IsExported() || (f.Anonymous && f.Type.Kind() == reflect.Struct)
+}
+
+// isNested checks if the field is a nested struct that should be loaded field by field.
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	pt := reflect.PointerTo(t)
+	return !pt.Implements(decoderType) && !pt.Implements(unmarshalerType)
+}
+
+func (l *structLoader) loadField(fv reflect.Value, path, key string, tags fieldTags) {
+	typ := typeName(fv.Type())
+	def := tags.def
+	if !tags.hasDef && !fv.IsZero() {
+		// keep the value set by SetDefaults
+		def = fmt.Sprint(fv.Interface())
+	}
+	// fields of the Secret type are always secret, even without a tag
+	secret := tags.secret || fv.Type() == secretType || fv.Type() == reflect.PointerTo(secretType)
+	Declare(Var{Name: key, Type: typ, Default: def, Description: tags.desc, Secret: secret, Required: tags.required, Group: l.group})
+	s := tags.def
+	if v, ok := Lookup(key); ok {
+		s = v.Value
+	} else if v, ok := Lookup(tags.alt); ok && tags.alt != "" {
+		s = v.Value
+	} else if !tags.hasDef {
+		// required fields only fail without a default, as in envconfig and caarlos0/env
+		if tags.required {
+			l.fail(path, key, ErrMissing)
+		}
+		return
+	}
+	if tags.file {
+		data, err := os.ReadFile(s)
+		if err != nil {
+			l.fail(path, key, err)
+			return
+		}
+		s = string(data)
+	}
+	if err := setField(fv, s, tags.sep); err != nil {
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			err = parseError(key, typ, s, err)
+		} else {
+			perr.Key = key
+		}
+		l.fail(path, key, err)
+	}
+}
+
+// typeName returns a name of the type as used in Var.Type.
+func typeName(t reflect.Type) string {
+	pt := reflect.PointerTo(t)
+	switch {
+	case pt.Implements(decoderType) || pt.Implements(unmarshalerType):
+		return "string"
+	case t == durationType:
+		return "duration"
+	case t.Kind() == reflect.String:
+		return "string"
+	case t.Kind() == reflect.Bool:
+		return "bool"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return "int"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "float64"
+	case t == reflect.TypeOf([]string(nil)):
+		return "strings"
+	}
+	return t.String()
+}
+
+// setField parses the value and sets the field to it.
+func setField(fv reflect.Value, s, sep string) error {
+	if fv.Kind() == reflect.Pointer && !isNested(fv.Type()) {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setField(fv.Elem(), s, sep)
+	}
+	if fv.CanAddr() {
+		switch d := fv.Addr().Interface().(type) {
+		case Decoder:
+			return d.Decode(s)
+		case encoding.TextUnmarshaler:
+			return d.UnmarshalText([]byte(s))
+		}
+	}
+	t := fv.Type()
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return parseError("", "duration", s, err)
+		}
+		fv.SetInt(int64(d))
+		return nil
+	case t.Kind() == reflect.Slice:
+		parts := strings.Split(s, sep)
+		if s == "" {
+			parts = nil
+		}
+		out := reflect.MakeSlice(t, len(parts), len(parts))
+		for i, p := range parts {
+			if err := setField(out.Index(i), strings.TrimSpace(p), sep); err != nil {
+				return err
+			}
+		}
+		fv.Set(out)
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return parseError("", "bool", s, err)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return parseError("", "int", s, err)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return parseError("", "int", s, err)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return parseError("", "float64", s, err)
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", t)
+	}
+	return nil
+}
+
+// UpperSnake converts a Go identifier to upper snake case: "HTTPPort" becomes "HTTP_PORT".
+func UpperSnake(name string) string {
+	rs := []rune(name)
+	var sb strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
@@ -0,0 +1,272 @@
+package env
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEnv isolates the test from the process environment, see Isolate.
+func testEnv(t *testing.T, vals Map) {
+	restore := Isolate(vals)
+	oldLog := Log
+	Log = func(string, error) {}
+	t.Cleanup(func() {
+		Log = oldLog
+		restore()
+	})
+}
+
+type nativeDB struct {
+	Host string `default:"localhost"`
+	Port int    `env:"PORT" default:"5432"`
+}
+
+type nativeBase struct {
+	Name string
+}
+
+type nativeConfig struct {
+	nativeBase
+	HTTPPort int
+	Timeout  time.Duration `default:"5s"`
+	Tags     []string
+	Token    string `secret:"true" required:"true"`
+	Skipped  string `env:"-"`
+	DB       nativeDB
+	Cache    *nativeDB `env:"CACHE"`
+}
+
+func TestLoadNative(t *testing.T) {
+	testEnv(t, Map{
+		"NAME":       "app",
+		"HTTP_PORT":  "8080",
+		"TAGS":       "a, b",
+		"TOKEN":      "secret",
+		"SKIPPED":    "x",
+		"DB_HOST":    "db",
+		"CACHE_PORT": "6379",
+	})
+	var c nativeConfig
+	if err := Load(&c); err != nil {
+		t.Fatal(err)
+	}
+	exp := nativeConfig{
+		nativeBase: nativeBase{Name: "app"},
+		HTTPPort:   8080,
+		Timeout:    5 * time.Second,
+		Tags:       []string{"a", "b"},
+		Token:      "secret",
+		DB:         nativeDB{Host: "db", Port: 5432},
+		Cache:      &nativeDB{Host: "localhost", Port: 6379},
+	}
+	if !reflect.DeepEqual(c, exp) {
+		t.Fatalf("unexpected result:\n%+v\nvs\n%+v", c, exp)
+	}
+	for _, v := range Vars() {
+		if v.Name == "TOKEN" && (!v.Secret || !v.Required) {
+			t.Errorf("unexpected declaration: %+v", v)
+		}
+	}
+}
+
+type namedBase struct {
+	Host string
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	testEnv(t, Map{"HOST": "flat", "BASE_HOST": "prefixed", "PORT": "80"})
+	var c struct {
+		namedBase
+		Port int
+	}
+	if err := Load(&c); err != nil {
+		t.Fatal(err)
+	} else if c.Host != "flat" || c.Port != 80 {
+		t.Fatalf("unexpected result: %+v", c)
+	}
+	var c2 struct {
+		namedBase `env:"BASE"`
+	}
+	if err := Load(&c2); err != nil {
+		t.Fatal(err)
+	} else if c2.Host != "prefixed" {
+		t.Fatalf("unexpected result: %+v", c2)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	testEnv(t, Map{"PORT": "x"})
+	var c struct {
+		Port  int
+		Token string `required:"true"`
+	}
+	err := Load(&c)
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Key != "PORT" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !errors.Is(err, ErrMissing) {
+		t.Fatalf("expected a missing variable error: %v", err)
+	}
+}
+
+func TestLoadEmbeddedEnvConfig(t *testing.T) {
+	testEnv(t, Map{"APP_HOST": "flat", "APP_BASE_HOST": "prefixed", "APP_PORT": "80"})
+	var c struct {
+		namedBase
+		Port int
+	}
+	if err := EnvConfig.Load("APP_", &c); err != nil {
+		t.Fatal(err)
+	} else if c.Host != "flat" || c.Port != 80 {
+		t.Fatalf("unexpected result: %+v", c)
+	}
+	var c2 struct {
+		namedBase `envconfig:"base"`
+	}
+	if err := EnvConfig.Load("APP_", &c2); err != nil {
+		t.Fatal(err)
+	} else if c2.Host != "prefixed" {
+		t.Fatalf("unexpected result: %+v", c2)
+	}
+}
+
+func TestLoadSecretFields(t *testing.T) {
+	testEnv(t, Map{"TOKEN": "t1", "KEY": "k1", "PLAIN": "p"})
+	var c struct {
+		Token Secret
+		Key   *Secret
+		Plain string
+	}
+	if err := Load(&c); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Token.Destroy()
+	defer c.Key.Destroy()
+	if !c.Token.Equal([]byte("t1")) || !c.Key.Equal([]byte("k1")) {
+		t.Fatalf("unexpected values")
+	}
+	for _, v := range Vars() {
+		if exp := v.Name != "PLAIN"; v.Secret != exp {
+			t.Errorf("%s: secret = %v, expected %v", v.Name, v.Secret, exp)
+		}
+	}
+}
+
+type hookDB struct {
+	Host string
+	Port int
+
+	calls *[]string
+}
+
+func (db *hookDB) SetDefaults() {
+	db.Host, db.Port = "localhost", 5432
+}
+
+func (db *hookDB) Validate() error {
+	*db.calls = append(*db.calls, "db")
+	if db.Port > 65535 {
+		return errors.New("port out of range")
+	}
+	return nil
+}
+
+type upperName string
+
+func (n *upperName) Decode(s string) error {
+	if s == "" || strings.ContainsAny(s, " \t") {
+		return errors.New("invalid name")
+	}
+	*n = upperName(strings.ToUpper(s))
+	return nil
+}
+
+type hookConfig struct {
+	Name upperName
+	Addr netip.Addr `default:"127.0.0.1"`
+	DB   hookDB
+
+	calls []string
+}
+
+func (c *hookConfig) Validate() error {
+	c.calls = append(c.calls, "config")
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestLoadHooks(t *testing.T) {
+	testEnv(t, Map{"NAME": "app", "ADDR": "10.0.0.1", "DB_PORT": "6543"})
+	var c hookConfig
+	c.DB.calls = &c.calls
+	if err := Load(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "APP" || c.Addr != netip.MustParseAddr("10.0.0.1") {
+		t.Fatalf("unexpected result: %+v", c)
+	}
+	// the host is kept from SetDefaults, the port is read from the environment
+	if c.DB.Host != "localhost" || c.DB.Port != 6543 {
+		t.Fatalf("unexpected result: %+v", c.DB)
+	}
+	// nested structs are validated before the parent
+	if exp := []string{"db", "config"}; !reflect.DeepEqual(c.calls, exp) {
+		t.Fatalf("unexpected calls: %q", c.calls)
+	}
+	for _, v := range Vars() {
+		switch v.Name {
+		case "DB_HOST", "DB_PORT", "ADDR":
+			if exp := map[string]string{"DB_HOST": "localhost", "DB_PORT": "5432", "ADDR": "127.0.0.1"}[v.Name]; v.Default != exp {
+				t.Errorf("%s: unexpected default: %q", v.Name, v.Default)
+			}
+		}
+		if v.Name == "NAME" || v.Name == "ADDR" {
+			if v.Type != "string" {
+				t.Errorf("%s: unexpected type: %q", v.Name, v.Type)
+			}
+		}
+	}
+}
+
+func TestLoadFieldErrors(t *testing.T) {
+	testEnv(t, Map{"NAME": "a b", "ADDR": "localhost", "DB_PORT": "70000"})
+	var c hookConfig
+	c.DB.calls = &c.calls
+	err := Load(&c)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var got []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ferr *FieldError
+		if !errors.As(e, &ferr) {
+			t.Fatalf("unexpected error type: %T", e)
+		}
+		got = append(got, ferr.Path+"|"+ferr.Key)
+		if ferr.Key != "" {
+			var perr *ParseError
+			if !errors.As(ferr, &perr) || perr.Key != ferr.Key {
+				t.Errorf("%s: expected a parse error for the key: %v", ferr.Path, ferr.Err)
+			}
+		}
+	}
+	exp := []string{
+		"hookConfig.Name|NAME",
+		"hookConfig.Addr|ADDR",
+		"hookConfig.DB|",
+		"hookConfig|",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected errors:\n%q\nvs\n%q", got, exp)
+	}
+	if s := err.Error(); !strings.Contains(s, "hookConfig.DB: port out of range") || !strings.Contains(s, "hookConfig.Name (NAME): ") {
+		t.Fatalf("unexpected error message: %s", s)
+	}
+}
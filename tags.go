@@ -0,0 +1,137 @@
+package env
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Dialect selects struct tag conventions understood by Load.
+type Dialect int
+
+const (
+	// Native is the default dialect of this package, see Load.
+	Native Dialect = iota
+	// EnvConfig understands tags of github.com/kelseyhightower/envconfig:
+	//
+	//	envconfig:"name"      - variable name, without prefix; also looked up without prefix if not set
+	//	split_words:"true"    - field name is converted to upper snake case instead of being upper-cased
+	//	default:"value"
+	//	required:"true"
+	//	ignored:"true"
+	//	desc:"description"
+	//
+	// Embedded structs without the envconfig tag are flattened, like envconfig does.
+	EnvConfig
+	// CaarlosEnv understands tags of github.com/caarlos0/env:
+	//
+	//	env:"KEY,required,notEmpty,file"  - fields without this tag are skipped, except nested structs
+	//	envDefault:"value"
+	//	envSeparator:":"
+	//	envPrefix:"DB_"                   - prefix for nested structs
+	CaarlosEnv
+)
+
+// fieldTags are options of a struct field parsed from its tags.
+type fieldTags struct {
+	key      string // variable name, without prefix
+	alt      string // alternative variable name to look up if key is not set
+	prefix   string // prefix for fields of a nested struct
+	def      string
+	hasDef   bool
+	desc     string
+	secret   bool
+	required bool
+	file     bool   // value is a name of the file to read
+	sep      string // separator for slices
+}
+
+// parseTags parses tags of the field. It returns false if the field must be skipped.
+func (d Dialect) parseTags(f reflect.StructField, nested bool) (fieldTags, bool) {
+	switch d {
+	case EnvConfig:
+		t, ok := parseEnvConfigTags(f)
+		if nested && f.Anonymous && f.Tag.Get("envconfig") == "" {
+			// embedded structs are flattened, unless named explicitly
+			t.prefix = ""
+		}
+		return t, ok
+	case CaarlosEnv:
+		return parseCaarlosTags(f, nested)
+	}
+	t := fieldTags{sep: ","}
+	name, ok := f.Tag.Lookup("env")
+	if name == "-" {
+		return t, false
+	}
+	explicit := ok && name != ""
+	if !explicit {
+		name = UpperSnake(f.Name)
+	}
+	t.key, t.prefix = name, name+"_"
+	if nested && f.Anonymous && !explicit {
+		// embedded structs are flattened, unless named explicitly
+		t.prefix = ""
+	}
+	t.def, t.hasDef = f.Tag.Lookup("default")
+	t.desc = f.Tag.Get("desc")
+	t.secret = tagBool(f, "secret")
+	t.required = tagBool(f, "required")
+	return t, true
+}
+
+func tagBool(f reflect.StructField, name string) bool {
+	v, _ := strconv.ParseBool(f.Tag.Get(name))
+	return v
+}
+
+func parseEnvConfigTags(f reflect.StructField) (fieldTags, bool) {
+	t := fieldTags{sep: ","}
+	if tagBool(f, "ignored") {
+		return t, false
+	}
+	name := f.Name
+	if tagBool(f, "split_words") {
+		name = UpperSnake(name)
+	}
+	if s := f.Tag.Get("envconfig"); s != "" {
+		name = s
+		t.alt = strings.ToUpper(s)
+	}
+	t.key = strings.ToUpper(name)
+	t.prefix = t.key + "_"
+	t.def, t.hasDef = f.Tag.Lookup("default")
+	t.desc = f.Tag.Get("desc")
+	t.required = tagBool(f, "required")
+	return t, true
+}
+
+func parseCaarlosTags(f reflect.StructField, nested bool) (fieldTags, bool) {
+	t := fieldTags{sep: ","}
+	tag, ok := f.Tag.Lookup("env")
+	if nested {
+		t.prefix = f.Tag.Get("envPrefix")
+		return t, tag != "-"
+	}
+	if !ok || tag == "-" {
+		return t, false
+	}
+	opts := strings.Split(tag, ",")
+	t.key = opts[0]
+	if t.key == "" {
+		return t, false
+	}
+	for _, o := range opts[1:] {
+		switch o {
+		case "required", "notEmpty":
+			t.required = true
+		case "file":
+			t.file = true
+		}
+	}
+	t.def, t.hasDef = f.Tag.Lookup("envDefault")
+	if s := f.Tag.Get("envSeparator"); s != "" {
+		t.sep = s
+	}
+	return t, true
+}
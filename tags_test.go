@@ -0,0 +1,267 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type dialectCase struct {
+	name   string
+	vals   Map
+	prefix string
+	dst    any // pointer to a zero struct
+	exp    any // expected struct value
+	err    string
+}
+
+func runDialectCases(t *testing.T, d Dialect, cases []dialectCase) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			testEnv(t, c.vals)
+			err := d.Load(c.prefix, c.dst)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q, got %v", c.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(c.dst).Elem().Interface(); !reflect.DeepEqual(got, c.exp) {
+				t.Fatalf("unexpected result:\n%+v\nvs\n%+v", got, c.exp)
+			}
+		})
+	}
+}
+
+type nativeTags struct {
+	Name    string `env:"APP_NAME" default:"app"`
+	Port    int    `default:"80"`
+	Debug   bool
+	Ignored string `env:"-"`
+}
+
+type nativeRequired struct {
+	Token string `required:"true"`
+}
+
+type requiredDefault struct {
+	Port int `required:"true" default:"80"`
+}
+
+type caarlosRequiredDefault struct {
+	Port int    `env:"PORT,required" envDefault:"80"`
+	Name string `env:"NAME,notEmpty" envDefault:"app"`
+}
+
+func TestNativeDialect(t *testing.T) {
+	runDialectCases(t, Native, []dialectCase{
+		{
+			name: "defaults",
+			dst:  &nativeTags{},
+			exp:  nativeTags{Name: "app", Port: 80},
+		},
+		{
+			name:   "prefix",
+			prefix: "X_",
+			vals:   Map{"X_APP_NAME": "svc", "X_PORT": "81", "X_DEBUG": "true", "X_IGNORED": "x", "PORT": "1"},
+			dst:    &nativeTags{},
+			exp:    nativeTags{Name: "svc", Port: 81, Debug: true},
+		},
+		{
+			name: "required",
+			dst:  &nativeRequired{},
+			err:  "Token (TOKEN): variable is not set",
+		},
+		{
+			name: "required with default",
+			dst:  &requiredDefault{},
+			exp:  requiredDefault{Port: 80},
+		},
+	})
+}
+
+type envConfigTags struct {
+	MaxConns   int    `split_words:"true" default:"10"`
+	Plain      string `default:"x"`
+	ListenAddr string `envconfig:"listen_addr"`
+	Timeout    time.Duration
+	Hosts      []string
+	Ignored    string `ignored:"true"`
+	Required   string `required:"true"`
+	DB         struct {
+		UserName string `split_words:"true"`
+	}
+}
+
+func TestEnvConfigDialect(t *testing.T) {
+	runDialectCases(t, EnvConfig, []dialectCase{
+		{
+			name:   "names",
+			prefix: "APP_",
+			vals: Map{
+				"APP_MAX_CONNS":    "20",
+				"APP_PLAIN":        "y",
+				"APP_LISTEN_ADDR":  ":80",
+				"APP_TIMEOUT":      "1m",
+				"APP_HOSTS":        "a,b",
+				"APP_IGNORED":      "x",
+				"APP_REQUIRED":     "r",
+				"APP_DB_USER_NAME": "u",
+			},
+			dst: &envConfigTags{},
+			exp: envConfigTags{
+				MaxConns: 20, Plain: "y", ListenAddr: ":80", Timeout: time.Minute,
+				Hosts: []string{"a", "b"}, Required: "r",
+				DB: struct {
+					UserName string `split_words:"true"`
+				}{UserName: "u"},
+			},
+		},
+		{
+			name:   "split words only when tagged",
+			prefix: "APP_",
+			vals:   Map{"APP_MAXCONNS": "20", "APP_REQUIRED": "r"},
+			dst:    &envConfigTags{},
+			exp:    envConfigTags{MaxConns: 10, Plain: "x", Required: "r"},
+		},
+		{
+			name:   "alternative name without prefix",
+			prefix: "APP_",
+			vals:   Map{"LISTEN_ADDR": ":81", "APP_REQUIRED": "r"},
+			dst:    &envConfigTags{},
+			exp:    envConfigTags{MaxConns: 10, Plain: "x", ListenAddr: ":81", Required: "r"},
+		},
+		{
+			name:   "prefixed name takes precedence",
+			prefix: "APP_",
+			vals:   Map{"LISTEN_ADDR": ":81", "APP_LISTEN_ADDR": ":82", "APP_REQUIRED": "r"},
+			dst:    &envConfigTags{},
+			exp:    envConfigTags{MaxConns: 10, Plain: "x", ListenAddr: ":82", Required: "r"},
+		},
+		{
+			name:   "required",
+			prefix: "APP_",
+			dst:    &envConfigTags{},
+			err:    "Required (APP_REQUIRED): variable is not set",
+		},
+		{
+			name: "required with default",
+			dst:  &requiredDefault{},
+			exp:  requiredDefault{Port: 80},
+		},
+		{
+			name: "required with default set",
+			vals: Map{"PORT": "81"},
+			dst:  &requiredDefault{},
+			exp:  requiredDefault{Port: 81},
+		},
+		{
+			name:   "invalid value",
+			prefix: "APP_",
+			vals:   Map{"APP_MAX_CONNS": "x", "APP_REQUIRED": "r"},
+			dst:    &envConfigTags{},
+			err:    "MaxConns (APP_MAX_CONNS)",
+		},
+	})
+}
+
+type caarlosDB struct {
+	Host string `env:"HOST" envDefault:"localhost"`
+}
+
+type caarlosTags struct {
+	Port     int           `env:"PORT" envDefault:"8080"`
+	Timeout  time.Duration `env:"TIMEOUT"`
+	Paths    []string      `env:"PATHS" envSeparator:":"`
+	Token    string        `env:"TOKEN,required"`
+	Name     string        `env:"NAME,notEmpty"`
+	Password string        `env:"PASSWORD_PATH,file"`
+	NoTag    string
+	Skipped  string    `env:"-"`
+	DB       caarlosDB `envPrefix:"DB_"`
+	Flat     caarlosDB
+}
+
+func TestCaarlosDialect(t *testing.T) {
+	dir := t.TempDir()
+	pass := filepath.Join(dir, "pass")
+	if err := os.WriteFile(pass, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	runDialectCases(t, CaarlosEnv, []dialectCase{
+		{
+			name: "tags",
+			vals: Map{
+				"PORT":          "9090",
+				"TIMEOUT":       "5s",
+				"PATHS":         "/a:/b",
+				"TOKEN":         "t",
+				"NAME":          "n",
+				"PASSWORD_PATH": pass,
+				"NO_TAG":        "x",
+				"NOTAG":         "x",
+				"SKIPPED":       "x",
+				"DB_HOST":       "db",
+				"HOST":          "flat",
+			},
+			dst: &caarlosTags{},
+			exp: caarlosTags{
+				Port: 9090, Timeout: 5 * time.Second, Paths: []string{"/a", "/b"},
+				Token: "t", Name: "n", Password: "secret",
+				DB: caarlosDB{Host: "db"}, Flat: caarlosDB{Host: "flat"},
+			},
+		},
+		{
+			name: "defaults",
+			vals: Map{"TOKEN": "t", "NAME": "n", "PASSWORD_PATH": pass},
+			dst:  &caarlosTags{},
+			exp: caarlosTags{
+				Port: 8080, Token: "t", Name: "n", Password: "secret",
+				DB: caarlosDB{Host: "localhost"}, Flat: caarlosDB{Host: "localhost"},
+			},
+		},
+		{
+			name: "required",
+			vals: Map{"NAME": "n", "PASSWORD_PATH": pass},
+			dst:  &caarlosTags{},
+			err:  "Token (TOKEN): variable is not set",
+		},
+		{
+			name: "not empty",
+			vals: Map{"TOKEN": "t", "NAME": "", "PASSWORD_PATH": pass},
+			dst:  &caarlosTags{},
+			err:  "Name (NAME): variable is not set",
+		},
+		{
+			name: "required with default",
+			dst:  &caarlosRequiredDefault{},
+			exp:  caarlosRequiredDefault{Port: 80, Name: "app"},
+		},
+		{
+			name: "missing file",
+			vals: Map{"TOKEN": "t", "NAME": "n", "PASSWORD_PATH": filepath.Join(dir, "missing")},
+			dst:  &caarlosTags{},
+			err:  "Password (PASSWORD_PATH)",
+		},
+	})
+}
+
+func TestDialectFieldErrors(t *testing.T) {
+	testEnv(t, Map{"PORT": "x"})
+	var c caarlosTags
+	err := CaarlosEnv.Load("", &c)
+	var ferr *FieldError
+	if !errors.As(err, &ferr) {
+		t.Fatalf("expected a field error, got %v", err)
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Key != "PORT" || perr.Type != "int" {
+		t.Fatalf("expected a parse error for PORT, got %v", err)
+	}
+}
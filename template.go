@@ -0,0 +1,104 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// ErrMissing is returned when a required variable is not set.
+var ErrMissing = errors.New("variable is not set")
+
+// Funcs returns template functions for reading variables:
+//
+//	env "KEY" ["default"]       - string value of the variable
+//	envBool "KEY" [default]     - typed values, parsed the same way as getters do;
+//	envInt "KEY" [default]        defaults can be given either as typed values or as strings
+//	envFloat "KEY" [default]
+//	envDuration "KEY" [default]
+//	envBytes "KEY" [default]
+//	required "KEY"              - string value of the variable; fails if it's not set
+//	default DEF VALUE           - VALUE, or DEF if VALUE is empty: {{ env "HOST" | default "localhost" }}
+//	quote VALUE                 - double-quoted string with Go escapes
+//	squote VALUE                - single-quoted string with quotes doubled, as in YAML and SQL ('it''s')
+//	shellQuote VALUE            - string quoted for POSIX shell
+//
+// Functions fail with *ParseError if the value has a wrong format. Typed functions and required also fail
+// with a *ParseError wrapping ErrMissing if neither the variable nor the default is set.
+// Unlike getters, they never log errors.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"env": func(key string, def ...string) (string, error) {
+			var s string
+			if len(def) > 1 {
+				return "", fmt.Errorf("%s: expected at most one default value", key)
+			} else if len(def) == 1 {
+				s = def[0]
+			}
+			declare(key, "string", s)
+			if v, ok := Lookup(key); ok {
+				return v.Value, nil
+			}
+			return s, nil
+		},
+		"envBool":     typedFunc("bool"),
+		"envInt":      typedFunc("int"),
+		"envFloat":    typedFunc("float64"),
+		"envDuration": typedFunc("duration"),
+		"envBytes":    typedFunc("bytes"),
+		"required": func(key string) (string, error) {
+			Declare(Var{Name: key, Required: true})
+			if v, ok := Lookup(key); ok {
+				return v.Value, nil
+			}
+			return "", fmt.Errorf("%s: %w", key, parseError(key, "string", "", ErrMissing))
+		},
+		"default": func(def, v any) any {
+			if v == nil || fmt.Sprint(v) == "" {
+				return def
+			}
+			return v
+		},
+		"quote":  func(v any) string { return strconv.Quote(fmt.Sprint(v)) },
+		"squote": func(v any) string { return "'" + strings.ReplaceAll(fmt.Sprint(v), "'", "''") + "'" },
+		"shellQuote": func(v any) string {
+			return "'" + strings.ReplaceAll(fmt.Sprint(v), "'", `'\''`) + "'"
+		},
+	}
+}
+
+// typedFunc returns a template function reading a variable of a given type.
+func typedFunc(typ string) func(key string, def ...any) (any, error) {
+	return func(key string, def ...any) (any, error) {
+		var s string
+		if len(def) > 1 {
+			return nil, fmt.Errorf("%s: expected at most one default value", key)
+		} else if len(def) == 1 {
+			s = fmt.Sprint(def[0])
+		}
+		declare(key, typ, s)
+		if v, ok := Lookup(key); ok {
+			s = v.Value
+		}
+		if s == "" {
+			return nil, fmt.Errorf("%s: %w", key, parseError(key, typ, "", ErrMissing))
+		}
+		v, err := Parse(key, typ, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		return v, nil
+	}
+}
+
+// Render executes a template with Funcs and writes the result to w. It can be used as a typed replacement for envsubst.
+func Render(w io.Writer, name, text string) error {
+	t, err := template.New(name).Funcs(Funcs()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, nil)
+}
@@ -0,0 +1,57 @@
+package env
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRenderQuotes(t *testing.T) {
+	testEnv(t, Map{"V": `it's "x"`})
+	for _, c := range []struct {
+		tmpl string
+		exp  string
+	}{
+		{`{{ env "V" | quote }}`, `"it's \"x\""`},
+		{`{{ env "V" | squote }}`, `'it''s "x"'`},
+		{`{{ env "V" | shellQuote }}`, `'it'\''s "x"'`},
+		{`{{ env "MISSING" | default "d" | squote }}`, `'d'`},
+	} {
+		var sb strings.Builder
+		if err := Render(&sb, "test", c.tmpl); err != nil {
+			t.Fatal(err)
+		} else if sb.String() != c.exp {
+			t.Errorf("%s: got %s, expected %s", c.tmpl, sb.String(), c.exp)
+		}
+	}
+}
+
+func TestRenderDefaults(t *testing.T) {
+	testEnv(t, Map{"V": "v"})
+	for _, c := range []struct {
+		tmpl string
+		exp  string
+	}{
+		{`{{ env "V" "d" }}`, "v"},
+		{`{{ env "MISSING" }}`, ""},
+		{`{{ env "MISSING" "d" }}`, "d"},
+		{`{{ envInt "MISSING" 5 }}`, "5"},
+		{`{{ envInt "MISSING" "5" }}`, "5"},
+	} {
+		var sb strings.Builder
+		if err := Render(&sb, "test", c.tmpl); err != nil {
+			t.Fatal(err)
+		} else if sb.String() != c.exp {
+			t.Errorf("%s: got %s, expected %s", c.tmpl, sb.String(), c.exp)
+		}
+	}
+	for _, tmpl := range []string{
+		`{{ env "MISSING" "a" "b" }}`,
+		`{{ env "V" "a" "b" }}`,
+		`{{ envInt "MISSING" 1 2 }}`,
+	} {
+		if err := Render(io.Discard, "test", tmpl); err == nil || !strings.Contains(err.Error(), "expected at most one default value") {
+			t.Errorf("%s: unexpected error: %v", tmpl, err)
+		}
+	}
+}
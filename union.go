@@ -0,0 +1,140 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type union struct {
+	names    []string // sorted
+	variants map[string]reflect.Type
+}
+
+var (
+	unionMu sync.RWMutex
+	unions  = make(map[reflect.Type]*union)
+)
+
+// RegisterUnion registers variants of a tagged union for the interface type T. Struct fields of type T
+// are then loaded by Load as follows: the field variable (a discriminator) selects the variant by name,
+// and only the selected variant struct is loaded, from variables prefixed with the discriminator and the variant name.
+//
+// For example, with variants registered as:
+//
+//	env.RegisterUnion(map[string]Storage{
+//		"s3":  &S3Config{},
+//		"gcs": &GCSConfig{},
+//	})
+//
+// a field `Storage Storage` with STORAGE=s3 is set to a new *S3Config, loaded from STORAGE_S3_* variables.
+// Variables of all variants are declared in the registry, grouped by the discriminator value (see Var.Group).
+func RegisterUnion[T any](variants map[string]T) {
+	it := reflect.TypeOf((*T)(nil)).Elem()
+	if it.Kind() != reflect.Interface {
+		panic(fmt.Errorf("env: union type must be an interface, got %v", it))
+	}
+	u := &union{variants: make(map[string]reflect.Type)}
+	for name, v := range variants {
+		t := reflect.TypeOf(v)
+		if t == nil || !isNested(t) {
+			panic(fmt.Errorf("env: union variant %q must be a struct or a pointer to a struct, got %v", name, t))
+		}
+		u.names = append(u.names, name)
+		u.variants[name] = t
+	}
+	sort.Strings(u.names)
+	unionMu.Lock()
+	defer unionMu.Unlock()
+	unions[it] = u
+}
+
+func lookupUnion(t reflect.Type) *union {
+	if t.Kind() != reflect.Interface {
+		return nil
+	}
+	unionMu.RLock()
+	defer unionMu.RUnlock()
+	return unions[t]
+}
+
+// variantPrefix returns a prefix for variables of the union variant.
+func variantPrefix(key, name string) string {
+	return key + "_" + strings.ToUpper(NormalizeSeparators(name)) + "_"
+}
+
+func (l *structLoader) loadUnion(fv reflect.Value, path, key string, tags fieldTags, u *union) {
+	Declare(Var{Name: key, Type: "string", Default: tags.def, Description: tags.desc, Required: tags.required, Enum: u.names, Group: l.group})
+	group := l.group
+	for _, name := range u.names {
+		l.group = key + "=" + name
+		l.declareStruct(u.variants[name], variantPrefix(key, name))
+	}
+	l.group = group
+
+	name := tags.def
+	if v, ok := Lookup(key); ok {
+		name = v.Value
+	}
+	if name == "" {
+		if tags.required {
+			l.fail(path, key, ErrMissing)
+		}
+		return
+	}
+	t, ok := u.variants[name]
+	if !ok {
+		l.fail(path, key, parseError(key, "string", name, fmt.Errorf("must be one of %s", strings.Join(u.names, ", "))))
+		return
+	}
+	pv := reflect.New(deref(t))
+	l.group = key + "=" + name
+	l.loadStruct(pv.Elem(), path, variantPrefix(key, name))
+	l.group = group
+	if t.Kind() == reflect.Pointer {
+		fv.Set(pv)
+	} else {
+		fv.Set(pv.Elem())
+	}
+}
+
+func deref(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// declareStruct declares variables of the struct type in the registry without reading them.
+func (l *structLoader) declareStruct(t reflect.Type, prefix string) {
+	t = deref(t)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !loadable(f) {
+			continue
+		}
+		nested := isNested(f.Type)
+		tags, ok := l.dialect.parseTags(f, nested)
+		if !ok {
+			continue
+		}
+		switch u := lookupUnion(f.Type); {
+		case u != nil:
+			key := prefix + tags.key
+			Declare(Var{Name: key, Type: "string", Default: tags.def, Description: tags.desc, Required: tags.required, Enum: u.names, Group: l.group})
+			group := l.group
+			for _, name := range u.names {
+				l.group = key + "=" + name
+				l.declareStruct(u.variants[name], variantPrefix(key, name))
+			}
+			l.group = group
+		case nested:
+			l.declareStruct(f.Type, prefix+tags.prefix)
+		default:
+			Declare(Var{Name: prefix + tags.key, Type: typeName(f.Type), Default: tags.def, Description: tags.desc,
+				Secret: tags.secret, Required: tags.required, Group: l.group})
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testStorage interface {
+	storageKind() string
+}
+
+type s3Storage struct {
+	Bucket string `required:"true"`
+	Region string `default:"us-east-1"`
+}
+
+func (*s3Storage) storageKind() string { return "s3" }
+
+type gcsStorage struct {
+	Bucket  string
+	Project string
+}
+
+func (gcsStorage) storageKind() string { return "gcs" }
+
+func init() {
+	RegisterUnion(map[string]testStorage{
+		"s3":  &s3Storage{},
+		"gcs": gcsStorage{},
+	})
+}
+
+type unionConfig struct {
+	Storage testStorage `default:"s3" desc:"storage backend"`
+}
+
+// recordingSource records all variables that were looked up.
+type recordingSource struct {
+	Map
+	keys []string
+}
+
+func (s *recordingSource) Lookup(key string) (val, from string, ok bool) {
+	s.keys = append(s.keys, key)
+	return s.Map.Lookup(key)
+}
+
+func TestLoadUnion(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		vals Map
+		exp  testStorage
+		err  string
+	}{
+		{
+			name: "default variant",
+			vals: Map{"STORAGE_S3_BUCKET": "b"},
+			exp:  &s3Storage{Bucket: "b", Region: "us-east-1"},
+		},
+		{
+			name: "value variant",
+			vals: Map{"STORAGE": "gcs", "STORAGE_GCS_BUCKET": "g", "STORAGE_GCS_PROJECT": "p", "STORAGE_S3_BUCKET": "b"},
+			exp:  gcsStorage{Bucket: "g", Project: "p"},
+		},
+		{
+			name: "unknown variant",
+			vals: Map{"STORAGE": "azure"},
+			err:  "Storage (STORAGE): must be one of gcs, s3",
+		},
+		{
+			name: "variant error",
+			vals: Map{"STORAGE": "s3"},
+			err:  "Storage.Bucket (STORAGE_S3_BUCKET): variable is not set",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			testEnv(t, nil)
+			src := &recordingSource{Map: c.vals}
+			restore := Isolate(src)
+			defer restore()
+			var conf unionConfig
+			err := Load(&conf)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(conf.Storage, c.exp) {
+				t.Fatalf("unexpected result: %#v", conf.Storage)
+			}
+			// only the selected variant is read
+			other := "STORAGE_GCS_"
+			if c.exp.storageKind() == "gcs" {
+				other = "STORAGE_S3_"
+			}
+			for _, k := range src.keys {
+				if strings.HasPrefix(k, other) {
+					t.Errorf("variable of another variant is read: %s", k)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadUnionRequired(t *testing.T) {
+	testEnv(t, nil)
+	var conf struct {
+		Storage testStorage `required:"true"`
+	}
+	if err := Load(&conf); !errors.Is(err, ErrMissing) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conf.Storage != nil {
+		t.Fatalf("unexpected result: %#v", conf.Storage)
+	}
+}
+
+func TestUnionDocs(t *testing.T) {
+	testEnv(t, Map{"STORAGE_S3_BUCKET": "b"})
+	var conf unionConfig
+	if err := Load(&conf); err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := WriteDocs(&sb); err != nil {
+		t.Fatal(err)
+	}
+	exp := "| Name | Type | Default | Description |\n|---|---|---|---|\n" +
+		"| `STORAGE` | string | `s3` | storage backend |\n" +
+		"\n#### `STORAGE=gcs`\n\n" +
+		"| Name | Type | Default | Description |\n|---|---|---|---|\n" +
+		"| `STORAGE_GCS_BUCKET` | string |  |  |\n" +
+		"| `STORAGE_GCS_PROJECT` | string |  |  |\n" +
+		"\n#### `STORAGE=s3`\n\n" +
+		"| Name | Type | Default | Description |\n|---|---|---|---|\n" +
+		"| `STORAGE_S3_BUCKET` | string |  |  |\n" +
+		"| `STORAGE_S3_REGION` | string | `us-east-1` |  |\n"
+	if sb.String() != exp {
+		t.Fatalf("unexpected docs:\n%s\nexpected:\n%s", sb.String(), exp)
+	}
+	for _, v := range Vars() {
+		if v.Name == "STORAGE" && !reflect.DeepEqual(v.Enum, []string{"gcs", "s3"}) {
+			t.Fatalf("unexpected enum: %v", v.Enum)
+		}
+	}
+}
+
+func TestRegisterUnionInvalid(t *testing.T) {
+	for _, fnc := range []func(){
+		func() { RegisterUnion(map[string]s3Storage{"s3": {}}) },
+		func() { RegisterUnion(map[string]any{"s": "string"}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			fnc()
+		}()
+	}
+}